@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenIssuer — значение claim "iss" в выпускаемых токенах
+const tokenIssuer = "support_front_api"
+
+// identityKey — ключ, под которым личность вызывающего хранится в gin.Context
+const identityKey = "auth.identity"
+
+// Роли вызывающих
+const (
+	// RoleCustomer — клиент, работающий только со своими тикетами
+	RoleCustomer = "customer"
+	// RoleSupport — сотрудник поддержки
+	RoleSupport = "support"
+	// RoleAdmin — администратор, имеет все права поддержки и может удалять данные
+	RoleAdmin = "admin"
+)
+
+// ValidRole проверяет, что роль известна
+func ValidRole(role string) bool {
+	switch role {
+	case RoleCustomer, RoleSupport, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// ErrInvalidToken возвращается при неверной подписи, формате или сроке действия токена
+var ErrInvalidToken = errors.New("недействительный токен")
+
+// Identity описывает аутентифицированного вызывающего
+type Identity struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// IsStaff сообщает, является ли вызывающий сотрудником поддержки или администратором
+func (i *Identity) IsStaff() bool {
+	return i.Role == RoleSupport || i.Role == RoleAdmin
+}
+
+// IsAdmin сообщает, является ли вызывающий администратором
+func (i *Identity) IsAdmin() bool {
+	return i.Role == RoleAdmin
+}
+
+// SenderType возвращает тип отправителя сообщений для роли: 'user' или 'support'
+func (i *Identity) SenderType() string {
+	if i.IsStaff() {
+		return "support"
+	}
+	return "user"
+}
+
+// HasRole сообщает, входит ли роль вызывающего в перечисленные.
+// Администратор имеет все права поддержки.
+func (i *Identity) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if i.Role == role || (role == RoleSupport && i.Role == RoleAdmin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims — содержимое JWT
+type Claims struct {
+	UserID int64  `json:"uid"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Issuer выпускает и проверяет JWT, подписанные HMAC-SHA256
+type Issuer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewIssuer создает Issuer с указанным секретом и временем жизни токенов
+func NewIssuer(secret string, ttl time.Duration) *Issuer {
+	return &Issuer{secret: []byte(secret), ttl: ttl}
+}
+
+// Issue выпускает токен для identity и возвращает его вместе со временем истечения
+func (i *Issuer) Issue(identity Identity) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.ttl)
+
+	claims := Claims{
+		UserID: identity.UserID,
+		Role:   identity.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.FormatInt(identity.UserID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("ошибка при подписи токена: %v", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// Parse проверяет подпись и срок действия токена и возвращает личность вызывающего
+func (i *Issuer) Parse(tokenString string) (*Identity, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		return i.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !ValidRole(claims.Role) {
+		return nil, fmt.Errorf("%w: неизвестная роль %q", ErrInvalidToken, claims.Role)
+	}
+
+	return &Identity{UserID: claims.UserID, Role: claims.Role}, nil
+}
+
+// Middleware требует заголовок "Authorization: Bearer <token>" и кладет личность в контекст
+func (i *Issuer) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		tokenString, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || strings.TrimSpace(tokenString) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Требуется авторизация"})
+			return
+		}
+
+		identity, err := i.Parse(strings.TrimSpace(tokenString))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Недействительный токен"})
+			return
+		}
+
+		SetIdentity(c, identity)
+		c.Next()
+	}
+}
+
+// RequireRole пропускает только вызывающих с одной из перечисленных ролей
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := FromContext(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Требуется авторизация"})
+			return
+		}
+		if !identity.HasRole(roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// SetIdentity сохраняет личность вызывающего в контексте запроса
+func SetIdentity(c *gin.Context, identity *Identity) {
+	c.Set(identityKey, identity)
+}
+
+// FromContext возвращает личность, сохраненную Middleware
+func FromContext(c *gin.Context) (*Identity, bool) {
+	value, ok := c.Get(identityKey)
+	if !ok {
+		return nil, false
+	}
+	identity, ok := value.(*Identity)
+	return identity, ok
+}
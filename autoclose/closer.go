@@ -0,0 +1,218 @@
+package autoclose
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"support_front_api/config"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository"
+	"time"
+)
+
+// closerLockID — ключ advisory-блокировки: проверку в каждый момент выполняет один экземпляр
+const closerLockID = 727100003
+
+// defaultInterval — период проверки тикетов по умолчанию
+const defaultInterval = time.Hour
+
+// day — длительность дня ожидания
+const day = 24 * time.Hour
+
+// Closer напоминает клиентам о тикетах, в которых поддержка ждет их ответа,
+// и закрывает тикеты, если клиент так и не ответил. Сообщение клиента
+// в автоматически закрытый тикет переоткрывает его (см. обработчик AddMessage).
+type Closer struct {
+	store       repository.Store
+	outbox      *notify.Outbox
+	remindAfter time.Duration
+	closeAfter  time.Duration
+	interval    time.Duration
+}
+
+// NewCloser создает задачу автозакрытия и проверяет сроки конфигурации
+func NewCloser(store repository.Store, outbox *notify.Outbox, cfg config.AutoCloseConfig) (*Closer, error) {
+	if cfg.RemindAfterDays < 0 || cfg.CloseAfterDays < 0 {
+		return nil, fmt.Errorf("сроки автозакрытия не могут быть отрицательными")
+	}
+	if cfg.RemindAfterDays > 0 && cfg.CloseAfterDays > 0 && cfg.RemindAfterDays >= cfg.CloseAfterDays {
+		return nil, fmt.Errorf("напоминание (%d дн.) должно быть раньше автозакрытия (%d дн.)", cfg.RemindAfterDays, cfg.CloseAfterDays)
+	}
+
+	c := &Closer{
+		store:       store,
+		outbox:      outbox,
+		remindAfter: time.Duration(cfg.RemindAfterDays) * day,
+		closeAfter:  time.Duration(cfg.CloseAfterDays) * day,
+		interval:    defaultInterval,
+	}
+	if cfg.IntervalSeconds > 0 {
+		c.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	}
+	return c, nil
+}
+
+// enabled сообщает, задано ли хотя бы одно действие
+func (c *Closer) enabled() bool {
+	return c.remindAfter > 0 || c.closeAfter > 0
+}
+
+// Run проверяет тикеты с периодом interval до отмены ctx
+func (c *Closer) Run(ctx context.Context) {
+	if !c.enabled() {
+		logger.LogInfo("Автозакрытие тикетов не настроено, проверка не запускается")
+		return
+	}
+	logger.LogInfo("Запущено автозакрытие тикетов: напоминание через %v, закрытие через %v", c.remindAfter, c.closeAfter)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if reminded, closed, err := c.RunOnce(ctx); err != nil {
+			logger.LogError("Ошибка при автозакрытии тикетов: %v", err)
+		} else if reminded > 0 || closed > 0 {
+			logger.LogInfo("Автозакрытие тикетов: напоминаний %d, закрыто %d", reminded, closed)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.LogInfo("Автозакрытие тикетов остановлено")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce выполняет одну проверку и возвращает количество напоминаний и закрытых тикетов.
+// Каждый тикет обрабатывается во вложенной транзакции: ошибка по одному тикету
+// записывается в журнал и не мешает обработке остальных.
+// Если проверку уже выполняет другой экземпляр, возвращает нули.
+func (c *Closer) RunOnce(ctx context.Context) (int, int, error) {
+	reminded, closed := 0, 0
+	if !c.enabled() {
+		return 0, 0, nil
+	}
+
+	err := c.store.InTx(ctx, func(tx repository.Store) error {
+		locked, err := tx.Locks().TryLock(ctx, closerLockID)
+		if err != nil || !locked {
+			return err
+		}
+
+		now := time.Now()
+		threshold := c.closeAfter
+		if c.remindAfter > 0 {
+			threshold = c.remindAfter
+		}
+		tickets, err := tx.Tickets().ListWaiting(ctx, now.Add(-threshold))
+		if err != nil {
+			return err
+		}
+
+		for _, candidate := range tickets {
+			var action string
+			err := tx.InTx(ctx, func(ticketTx repository.Store) error {
+				var err error
+				action, err = c.process(ctx, ticketTx, candidate.ID, now)
+				return err
+			})
+			if err != nil {
+				logger.LogError("Ошибка при автозакрытии тикета %d: %v", candidate.ID, err)
+				continue
+			}
+			switch action {
+			case actionClose:
+				closed++
+			case actionRemind:
+				reminded++
+			}
+		}
+		return nil
+	})
+
+	return reminded, closed, err
+}
+
+// Действия с тикетом, ожидающим ответа клиента
+const (
+	actionNone   = ""
+	actionRemind = "remind"
+	actionClose  = "close"
+)
+
+// process напоминает о тикете или закрывает его, если срок ожидания истек, и возвращает выполненное действие
+func (c *Closer) process(ctx context.Context, tx repository.Store, ticketID int, now time.Time) (string, error) {
+	// Тикет перечитывается с блокировкой: клиент мог ответить после выборки
+	ticket, err := tx.Tickets().GetForUpdate(ctx, ticketID)
+	if err != nil {
+		return actionNone, err
+	}
+	if ticket.WaitingSince == nil || !models.IsActiveStatus(ticket.Status) {
+		return actionNone, nil
+	}
+
+	waited := now.Sub(*ticket.WaitingSince)
+	switch {
+	case c.closeAfter > 0 && waited >= c.closeAfter:
+		return actionClose, c.close(ctx, tx, ticket, now)
+	case c.remindAfter > 0 && waited >= c.remindAfter && ticket.StaleRemindedAt == nil:
+		return actionRemind, c.remind(ctx, tx, ticket, waited, now)
+	}
+	return actionNone, nil
+}
+
+// remind отправляет клиенту напоминание и отмечает его в тикете и истории
+func (c *Closer) remind(ctx context.Context, tx repository.Store, ticket *models.Ticket, waited time.Duration, now time.Time) error {
+	ticket.StaleRemindedAt = &now
+	if err := tx.Tickets().Update(ctx, ticket); err != nil {
+		return err
+	}
+
+	days := int(waited / day)
+	entry := models.NewHistoryEntry(ticket.ID, models.HistoryFieldReminder, "", strconv.Itoa(days), models.SystemActor(), now)
+	if err := tx.History().Add(ctx, entry); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Мы ждем вашего ответа по тикету %d.", ticket.ID)
+	if c.closeAfter > 0 {
+		left := int((c.closeAfter - waited + day - 1) / day)
+		message += fmt.Sprintf(" Если ответа не будет, тикет закроется автоматически через %d дн.", left)
+	}
+	return c.outbox.Enqueue(ctx, tx, notify.Notification{
+		Event:       notify.EventStaleReminder,
+		TicketID:    ticket.ID,
+		RecipientID: ticket.UserID,
+		Message:     message,
+	})
+}
+
+// close закрывает тикет от имени системы и уведомляет клиента, предлагая оценить работу поддержки
+func (c *Closer) close(ctx context.Context, tx repository.Store, ticket *models.Ticket, now time.Time) error {
+	oldStatus := ticket.Status
+	if err := ticket.ApplyStatus(models.StatusClosed, now); err != nil {
+		return err
+	}
+	ticket.AutoClosedAt = &now
+	ticket.WaitingSince = nil
+	ticket.StaleRemindedAt = nil
+
+	if err := tx.Tickets().Update(ctx, ticket); err != nil {
+		return err
+	}
+	entry := models.NewHistoryEntry(ticket.ID, models.HistoryFieldStatus, oldStatus, ticket.Status, models.SystemActor(), now)
+	if err := tx.History().Add(ctx, entry); err != nil {
+		return err
+	}
+
+	message := notify.StatusNotification(ticket.ID, ticket.Status, "мы не получили ответа, поэтому тикет закрыт автоматически")
+	return c.outbox.Enqueue(ctx, tx, notify.Notification{
+		Event:       notify.EventTicketUpdated,
+		TicketID:    ticket.ID,
+		RecipientID: ticket.UserID,
+		Message:     message + ". Если вопрос не решен, напишите в тикет, чтобы открыть его снова",
+	})
+}
@@ -0,0 +1,116 @@
+package autoclose
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"support_front_api/config"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository"
+	"support_front_api/repository/memory"
+	"testing"
+	"time"
+)
+
+// failingStore — хранилище, в котором сохранение тикета failID завершается ошибкой
+type failingStore struct {
+	repository.Store
+	failID int
+}
+
+func (s failingStore) Tickets() repository.TicketRepository {
+	return failingTickets{TicketRepository: s.Store.Tickets(), failID: s.failID}
+}
+
+func (s failingStore) InTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	return s.Store.InTx(ctx, func(tx repository.Store) error {
+		return fn(failingStore{Store: tx, failID: s.failID})
+	})
+}
+
+type failingTickets struct {
+	repository.TicketRepository
+	failID int
+}
+
+func (r failingTickets) Update(ctx context.Context, ticket *models.Ticket) error {
+	if ticket.ID == r.failID {
+		return errors.New("тикет не сохраняется")
+	}
+	return r.TicketRepository.Update(ctx, ticket)
+}
+
+func TestRunOnceContinuesAfterTicketError(t *testing.T) {
+	if err := logger.InitLogger(filepath.Join(t.TempDir(), "app.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	store := memory.NewStore()
+
+	now := time.Now()
+	createWaiting := func(days int) int {
+		since := now.Add(-time.Duration(days) * day)
+		ticket := models.Ticket{
+			UserID:       5,
+			Status:       models.StatusWaitingCustomer,
+			Priority:     models.DefaultPriority,
+			Category:     models.DefaultCategory,
+			CreatedAt:    since,
+			WaitingSince: &since,
+		}
+		if err := store.Tickets().Create(ctx, &ticket); err != nil {
+			t.Fatal(err)
+		}
+		return ticket.ID
+	}
+	failing := createWaiting(6)
+	toClose := createWaiting(5)
+	toRemind := createWaiting(2)
+
+	outbox := notify.NewOutbox([]notify.Channel{{Name: "superconnect", Notifier: notify.NewSuperconnect(config.SuperconnectConfig{})}})
+	closer, err := NewCloser(failingStore{Store: store, failID: failing}, outbox, config.AutoCloseConfig{RemindAfterDays: 1, CloseAfterDays: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reminded, closed, err := closer.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("ошибка одного тикета прервала проверку: %v", err)
+	}
+	if reminded != 1 || closed != 1 {
+		t.Errorf("напоминаний %d, закрыто %d, ожидалось по одному", reminded, closed)
+	}
+
+	tests := []struct {
+		id         int
+		wantStatus string
+		reminded   bool
+	}{
+		{failing, models.StatusWaitingCustomer, false},
+		{toClose, models.StatusClosed, false},
+		{toRemind, models.StatusWaitingCustomer, true},
+	}
+	for _, tt := range tests {
+		ticket, err := store.Tickets().GetByID(ctx, tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ticket.Status != tt.wantStatus {
+			t.Errorf("тикет %d: статус %q, ожидался %q", tt.id, ticket.Status, tt.wantStatus)
+		}
+		if (ticket.StaleRemindedAt != nil) != tt.reminded {
+			t.Errorf("тикет %d: отметка напоминания %v", tt.id, ticket.StaleRemindedAt)
+		}
+	}
+
+	history, err := store.History().ListByTicket(ctx, failing, repository.Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Errorf("изменения тикета с ошибкой не отменены: %+v", history)
+	}
+}
@@ -0,0 +1,283 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockID — ключ advisory-блокировки, не дающей двум процессам
+// применять миграции одновременно
+const migrationLockID = 727100001
+
+// Migration представляет одну версию схемы базы данных
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus описывает состояние миграции в базе данных
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// LoadMigrations читает встроенные файлы миграций вида 0001_name.up.sql / 0001_name.down.sql
+func LoadMigrations() ([]Migration, error) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		base := path.Base(file)
+
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("неизвестный файл миграции: %s", base)
+		}
+
+		name := strings.TrimSuffix(base, "."+direction+".sql")
+		sep := strings.Index(name, "_")
+		if sep <= 0 {
+			return nil, fmt.Errorf("неверное имя файла миграции: %s", base)
+		}
+		version, err := strconv.Atoi(name[:sep])
+		if err != nil {
+			return nil, fmt.Errorf("неверная версия миграции %s: %v", base, err)
+		}
+
+		content, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name[sep+1:]}
+			byVersion[version] = m
+		} else if m.Name != name[sep+1:] {
+			return nil, fmt.Errorf("у версии %d несколько миграций: %s и %s", version, m.Name, name[sep+1:])
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("у миграции %04d_%s нет up-файла", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrateUp применяет все ещё не применённые миграции и возвращает их количество
+func MigrateUp(ctx context.Context, database *sql.DB) (int, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	err = withMigrationLock(ctx, database, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := versions[m.Version]; ok {
+				continue
+			}
+			if err := applyMigration(ctx, conn, m.Version, m.Name, m.Up, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// MigrateDown откатывает последние steps применённых миграций и возвращает их количество
+func MigrateDown(ctx context.Context, database *sql.DB, steps int) (int, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	reverted := 0
+	err = withMigrationLock(ctx, database, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		ordered := make([]int, 0, len(versions))
+		for v := range versions {
+			ordered = append(ordered, v)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(ordered)))
+
+		for _, v := range ordered {
+			if reverted >= steps {
+				break
+			}
+			m, ok := byVersion[v]
+			if !ok {
+				return fmt.Errorf("миграция версии %d применена, но отсутствует в сборке", v)
+			}
+			if m.Down == "" {
+				return fmt.Errorf("у миграции %04d_%s нет down-файла", m.Version, m.Name)
+			}
+			if err := applyMigration(ctx, conn, m.Version, m.Name, m.Down, false); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// GetMigrationStatus возвращает состояние всех известных миграций
+func GetMigrationStatus(ctx context.Context, database *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	versions, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if appliedAt, ok := versions[m.Version]; ok {
+			status.Applied = true
+			at := appliedAt
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// withMigrationLock выполняет fn на отдельном соединении под advisory-блокировкой
+func withMigrationLock(ctx context.Context, database *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("не удалось получить блокировку миграций: %v", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	return fn(conn)
+}
+
+// appliedVersions возвращает применённые версии и время их применения
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT        NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать таблицу schema_migrations: %v", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		versions[version] = appliedAt
+	}
+
+	return versions, rows.Err()
+}
+
+// applyMigration выполняет SQL миграции и обновляет schema_migrations в одной транзакции
+func applyMigration(ctx context.Context, conn *sql.Conn, version int, name, script string, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("ошибка при выполнении миграции %04d_%s: %v", version, name, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", version, name)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", version)
+	}
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("ошибка при записи версии миграции %04d_%s: %v", version, name, err)
+	}
+
+	return tx.Commit()
+}
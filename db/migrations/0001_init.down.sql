@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ticket_photos;
+DROP TABLE IF EXISTS ticket_messages;
+DROP TABLE IF EXISTS tickets;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,52 @@
+-- Базовая схема: пользователи, тикеты, сообщения и фотографии.
+-- IF NOT EXISTS позволяет применить миграцию к уже существующей базе.
+
+CREATE TABLE IF NOT EXISTS users (
+    id            BIGINT PRIMARY KEY,
+    full_name     TEXT             NOT NULL DEFAULT '',
+    phone         TEXT             NOT NULL DEFAULT '',
+    location_lat  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    location_lng  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    birth_date    DATE,
+    is_registered BOOLEAN          NOT NULL DEFAULT FALSE,
+    registered_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS tickets (
+    id          SERIAL PRIMARY KEY,
+    user_id     BIGINT       NOT NULL REFERENCES users (id),
+    title       TEXT         NOT NULL,
+    description TEXT         NOT NULL,
+    status      VARCHAR(50)  NOT NULL,
+    category    VARCHAR(100) NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    closed_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_tickets_user_id ON tickets (user_id);
+CREATE INDEX IF NOT EXISTS idx_tickets_status ON tickets (status);
+CREATE INDEX IF NOT EXISTS idx_tickets_created_at ON tickets (created_at);
+
+CREATE TABLE IF NOT EXISTS ticket_messages (
+    id          SERIAL PRIMARY KEY,
+    ticket_id   INTEGER     NOT NULL REFERENCES tickets (id),
+    sender_type VARCHAR(20) NOT NULL,
+    sender_id   BIGINT      NOT NULL,
+    message     TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_messages_ticket_id ON ticket_messages (ticket_id, created_at);
+
+CREATE TABLE IF NOT EXISTS ticket_photos (
+    id          SERIAL PRIMARY KEY,
+    ticket_id   INTEGER     NOT NULL REFERENCES tickets (id),
+    sender_type VARCHAR(20) NOT NULL,
+    sender_id   BIGINT      NOT NULL,
+    file_path   TEXT        NOT NULL,
+    file_id     VARCHAR(64) NOT NULL,
+    message_id  INTEGER REFERENCES ticket_messages (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_photos_ticket_id ON ticket_photos (ticket_id);
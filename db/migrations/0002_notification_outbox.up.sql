@@ -0,0 +1,20 @@
+-- Очередь уведомлений: запись создается в одной транзакции с изменением тикета,
+-- а доставляет ее фоновый диспетчер с повторными попытками.
+
+CREATE TABLE notification_outbox (
+    id              BIGSERIAL PRIMARY KEY,
+    channel         VARCHAR(100) NOT NULL,
+    event           VARCHAR(50)  NOT NULL,
+    ticket_id       INTEGER      NOT NULL,
+    recipient_id    BIGINT       NOT NULL,
+    message         TEXT         NOT NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    attempts        INTEGER      NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notification_outbox_due ON notification_outbox (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_notification_outbox_status ON notification_outbox (status, id);
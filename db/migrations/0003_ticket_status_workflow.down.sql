@@ -0,0 +1,5 @@
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_status_check;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS reopened_at,
+    DROP COLUMN IF EXISTS resolved_at;
@@ -0,0 +1,17 @@
+-- Фиксированный набор статусов тикета и отметки времени переходов.
+
+ALTER TABLE tickets
+    ADD COLUMN resolved_at TIMESTAMPTZ,
+    ADD COLUMN reopened_at TIMESTAMPTZ;
+
+-- Приводим произвольные статусы к допустимым: закрытые остаются закрытыми,
+-- остальные считаются открытыми
+UPDATE tickets SET status = 'закрыт'
+WHERE status NOT IN ('открыт', 'в работе', 'ожидает клиента', 'решен', 'закрыт', 'переоткрыт')
+  AND closed_at IS NOT NULL;
+
+UPDATE tickets SET status = 'открыт'
+WHERE status NOT IN ('открыт', 'в работе', 'ожидает клиента', 'решен', 'закрыт', 'переоткрыт');
+
+ALTER TABLE tickets ADD CONSTRAINT tickets_status_check
+    CHECK (status IN ('открыт', 'в работе', 'ожидает клиента', 'решен', 'закрыт', 'переоткрыт'));
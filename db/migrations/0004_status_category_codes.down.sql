@@ -0,0 +1,16 @@
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_status_check;
+
+UPDATE tickets SET status = CASE status
+    WHEN 'open' THEN 'открыт'
+    WHEN 'in_progress' THEN 'в работе'
+    WHEN 'waiting_customer' THEN 'ожидает клиента'
+    WHEN 'resolved' THEN 'решен'
+    WHEN 'closed' THEN 'закрыт'
+    WHEN 'reopened' THEN 'переоткрыт'
+    ELSE status
+END;
+
+UPDATE tickets SET category = 'спросить' WHERE category = 'question';
+
+ALTER TABLE tickets ADD CONSTRAINT tickets_status_check
+    CHECK (status IN ('открыт', 'в работе', 'ожидает клиента', 'решен', 'закрыт', 'переоткрыт'));
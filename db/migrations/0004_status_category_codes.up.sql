@@ -0,0 +1,18 @@
+-- Статусы и категория по умолчанию хранятся кодами, подписи формирует API.
+
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_status_check;
+
+UPDATE tickets SET status = CASE status
+    WHEN 'открыт' THEN 'open'
+    WHEN 'в работе' THEN 'in_progress'
+    WHEN 'ожидает клиента' THEN 'waiting_customer'
+    WHEN 'решен' THEN 'resolved'
+    WHEN 'закрыт' THEN 'closed'
+    WHEN 'переоткрыт' THEN 'reopened'
+    ELSE status
+END;
+
+UPDATE tickets SET category = 'question' WHERE category = 'спросить';
+
+ALTER TABLE tickets ADD CONSTRAINT tickets_status_check
+    CHECK (status IN ('open', 'in_progress', 'waiting_customer', 'resolved', 'closed', 'reopened'));
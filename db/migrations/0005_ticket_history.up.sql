@@ -0,0 +1,14 @@
+-- История изменений тикета: кто, когда и что поменял.
+
+CREATE TABLE ticket_history (
+    id         BIGSERIAL PRIMARY KEY,
+    ticket_id  INTEGER     NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    field      VARCHAR(50) NOT NULL,
+    old_value  TEXT,
+    new_value  TEXT,
+    actor_role VARCHAR(20) NOT NULL,
+    actor_id   BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ticket_history_ticket ON ticket_history (ticket_id, created_at, id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tickets_assignee_id;
+
+ALTER TABLE tickets DROP COLUMN IF EXISTS assignee_id;
+
+DROP TABLE IF EXISTS support_agents;
@@ -0,0 +1,14 @@
+-- Справочник сотрудников поддержки и ответственный за тикет.
+-- ID сотрудника совпадает с user_id в его токене.
+
+CREATE TABLE support_agents (
+    id         BIGINT PRIMARY KEY,
+    full_name  TEXT        NOT NULL,
+    email      TEXT        NOT NULL DEFAULT '',
+    is_active  BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE tickets ADD COLUMN assignee_id BIGINT REFERENCES support_agents (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tickets_assignee_id ON tickets (assignee_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS routing_cursors;
+
+ALTER TABLE support_agents
+    DROP COLUMN IF EXISTS on_shift,
+    DROP COLUMN IF EXISTS skills;
@@ -0,0 +1,11 @@
+-- Автоматическое назначение тикетов: навыки и смена сотрудников,
+-- позиция round-robin по каждой категории.
+
+ALTER TABLE support_agents
+    ADD COLUMN skills   TEXT[]  NOT NULL DEFAULT '{}',
+    ADD COLUMN on_shift BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE routing_cursors (
+    key           VARCHAR(100) PRIMARY KEY,
+    last_agent_id BIGINT       NOT NULL DEFAULT 0
+);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_tickets_resolution_due;
+DROP INDEX IF EXISTS idx_tickets_first_response_due;
+DROP INDEX IF EXISTS idx_tickets_priority;
+
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS tickets_priority_check;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS resolution_due,
+    DROP COLUMN IF EXISTS first_response_due,
+    DROP COLUMN IF EXISTS first_response_at,
+    DROP COLUMN IF EXISTS priority;
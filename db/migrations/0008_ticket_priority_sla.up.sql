@@ -0,0 +1,24 @@
+-- Приоритет тикета и сроки SLA: первый ответ поддержки и решение.
+
+ALTER TABLE tickets
+    ADD COLUMN priority           VARCHAR(20) NOT NULL DEFAULT 'normal',
+    ADD COLUMN first_response_at  TIMESTAMPTZ,
+    ADD COLUMN first_response_due TIMESTAMPTZ,
+    ADD COLUMN resolution_due     TIMESTAMPTZ;
+
+ALTER TABLE tickets ADD CONSTRAINT tickets_priority_check
+    CHECK (priority IN ('low', 'normal', 'high', 'urgent'));
+
+-- Первый ответ существующих тикетов — самое раннее сообщение поддержки
+UPDATE tickets t SET first_response_at = m.first_at
+FROM (
+    SELECT ticket_id, MIN(created_at) AS first_at
+    FROM ticket_messages
+    WHERE sender_type = 'support'
+    GROUP BY ticket_id
+) m
+WHERE m.ticket_id = t.id;
+
+CREATE INDEX idx_tickets_priority ON tickets (priority);
+CREATE INDEX idx_tickets_first_response_due ON tickets (first_response_due) WHERE first_response_at IS NULL;
+CREATE INDEX idx_tickets_resolution_due ON tickets (resolution_due) WHERE resolved_at IS NULL AND closed_at IS NULL;
@@ -0,0 +1,10 @@
+-- Сработавшие эскалации SLA: первичный ключ не дает выполнить одну эскалацию дважды,
+-- даже если ее обрабатывают несколько экземпляров API.
+
+CREATE TABLE ticket_escalations (
+    ticket_id  INTEGER     NOT NULL REFERENCES tickets (id) ON DELETE CASCADE,
+    deadline   VARCHAR(20) NOT NULL,
+    level      VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (ticket_id, deadline, level)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS resolution_risk_at,
+    DROP COLUMN IF EXISTS first_response_risk_at;
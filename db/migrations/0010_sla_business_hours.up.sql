@@ -0,0 +1,15 @@
+-- Моменты, с которых сроки SLA под угрозой. Вычисляются сервисом по рабочему календарю,
+-- поэтому хранятся вместе со сроками, а не выводятся из created_at.
+
+ALTER TABLE tickets
+    ADD COLUMN first_response_risk_at TIMESTAMPTZ,
+    ADD COLUMN resolution_risk_at     TIMESTAMPTZ;
+
+-- Существующим срокам порог назначается по прежнему правилу: последние 20% срока
+UPDATE tickets
+SET first_response_risk_at = first_response_due - (first_response_due - created_at) * 0.2
+WHERE first_response_due IS NOT NULL;
+
+UPDATE tickets
+SET resolution_risk_at = resolution_due - (resolution_due - created_at) * 0.2
+WHERE resolution_due IS NOT NULL;
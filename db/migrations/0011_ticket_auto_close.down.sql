@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_tickets_waiting_since;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS auto_closed_at,
+    DROP COLUMN IF EXISTS stale_reminded_at,
+    DROP COLUMN IF EXISTS waiting_since;
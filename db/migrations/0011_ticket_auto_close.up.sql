@@ -0,0 +1,19 @@
+-- Ожидание ответа клиента: напоминание и автозакрытие тикетов, в которых клиент не отвечает.
+
+ALTER TABLE tickets
+    ADD COLUMN waiting_since     TIMESTAMPTZ,
+    ADD COLUMN stale_reminded_at TIMESTAMPTZ,
+    ADD COLUMN auto_closed_at    TIMESTAMPTZ;
+
+-- Нерешенные тикеты, в которых последним писал сотрудник поддержки, ждут клиента с этого сообщения
+UPDATE tickets t SET waiting_since = m.created_at
+FROM (
+    SELECT DISTINCT ON (ticket_id) ticket_id, sender_type, created_at
+    FROM ticket_messages
+    ORDER BY ticket_id, created_at DESC, id DESC
+) m
+WHERE m.ticket_id = t.id
+  AND m.sender_type = 'support'
+  AND t.status NOT IN ('resolved', 'closed');
+
+CREATE INDEX idx_tickets_waiting_since ON tickets (waiting_since) WHERE waiting_since IS NOT NULL;
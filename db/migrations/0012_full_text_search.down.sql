@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_ticket_messages_search_vector;
+DROP INDEX IF EXISTS idx_tickets_search_vector;
+
+ALTER TABLE ticket_messages DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE tickets DROP COLUMN IF EXISTS search_vector;
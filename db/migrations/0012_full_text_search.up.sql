@@ -0,0 +1,16 @@
+-- Полнотекстовый поиск по тикетам и сообщениям с русским словарем.
+-- Векторы — генерируемые столбцы, поэтому Postgres сам пересчитывает их при изменении текста.
+
+ALTER TABLE tickets
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector('russian', COALESCE(description, '')), 'B')
+    ) STORED;
+
+ALTER TABLE ticket_messages
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('russian', COALESCE(message, ''))
+    ) STORED;
+
+CREATE INDEX idx_tickets_search_vector ON tickets USING GIN (search_vector);
+CREATE INDEX idx_ticket_messages_search_vector ON ticket_messages USING GIN (search_vector);
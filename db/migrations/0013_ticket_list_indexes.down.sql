@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tickets_created_at_id;
+DROP INDEX IF EXISTS idx_tickets_closed_at;
+DROP INDEX IF EXISTS idx_tickets_category;
@@ -0,0 +1,5 @@
+-- Индексы для фильтров и сортировки списка тикетов
+
+CREATE INDEX idx_tickets_category ON tickets (category);
+CREATE INDEX idx_tickets_closed_at ON tickets (closed_at);
+CREATE INDEX idx_tickets_created_at_id ON tickets (created_at, id);
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_ticket_messages_ticket_id ON ticket_messages (ticket_id, created_at);
+DROP INDEX IF EXISTS idx_ticket_messages_ticket_created_id;
@@ -0,0 +1,4 @@
+-- Индекс для постраничной выборки сообщений тикета по курсору (created_at, id)
+
+CREATE INDEX idx_ticket_messages_ticket_created_id ON ticket_messages (ticket_id, created_at, id);
+DROP INDEX IF EXISTS idx_ticket_messages_ticket_id;
@@ -0,0 +1,7 @@
+-- После отката тикеты из корзины снова видны в выборках
+
+DROP INDEX IF EXISTS idx_tickets_deleted_at;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
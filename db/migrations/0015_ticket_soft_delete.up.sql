@@ -0,0 +1,8 @@
+-- Корзина тикетов: удаленный тикет помечается deleted_at и скрывается из выборок
+-- до восстановления или безвозвратного удаления по сроку хранения
+
+ALTER TABLE tickets
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by BIGINT;
+
+CREATE INDEX idx_tickets_deleted_at ON tickets (deleted_at, id) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,6 @@
+-- Перенесенные сообщения и фотографии остаются в целевых тикетах
+
+DROP INDEX IF EXISTS idx_tickets_merged_into;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS merged_into_id;
@@ -0,0 +1,6 @@
+-- Объединение тикетов-дубликатов: закрытый дубликат ссылается на тикет, в который перенесена переписка
+
+ALTER TABLE tickets
+    ADD COLUMN merged_into_id INTEGER REFERENCES tickets (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tickets_merged_into ON tickets (merged_into_id) WHERE merged_into_id IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Связи между тикетами. Связь хранится один раз в прямом направлении (relates_to, duplicate_of,
+-- parent_of); у relates_to первым идет меньший ID. Пару тикетов можно связать только один раз,
+-- у дочернего тикета может быть только один родительский.
+
+CREATE TABLE ticket_links (
+    id                 BIGSERIAL PRIMARY KEY,
+    ticket_id          INTEGER     NOT NULL REFERENCES tickets (id) ON DELETE CASCADE,
+    linked_ticket_id   INTEGER     NOT NULL REFERENCES tickets (id) ON DELETE CASCADE,
+    type               VARCHAR(20) NOT NULL,
+    on_parent_resolved VARCHAR(20),
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ticket_id <> linked_ticket_id)
+);
+
+CREATE UNIQUE INDEX idx_ticket_links_pair ON ticket_links (LEAST(ticket_id, linked_ticket_id), GREATEST(ticket_id, linked_ticket_id));
+CREATE UNIQUE INDEX idx_ticket_links_parent ON ticket_links (linked_ticket_id) WHERE type = 'parent_of';
+CREATE INDEX idx_ticket_links_linked ON ticket_links (linked_ticket_id);
@@ -0,0 +1,3 @@
+ALTER TABLE ticket_messages
+    DROP COLUMN IF EXISTS mentions,
+    DROP COLUMN IF EXISTS kind;
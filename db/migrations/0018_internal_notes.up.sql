@@ -0,0 +1,6 @@
+-- Внутренние заметки: сообщения вида note видны только поддержке.
+-- В mentions хранятся ID сотрудников, упомянутых в заметке.
+
+ALTER TABLE ticket_messages
+    ADD COLUMN kind     VARCHAR(20) NOT NULL DEFAULT 'reply',
+    ADD COLUMN mentions BIGINT[]    NOT NULL DEFAULT '{}';
@@ -0,0 +1,15 @@
+-- Библиотека шаблонов ответов. Шаблон без owner_id общий и виден всем сотрудникам,
+-- с owner_id — личный шаблон сотрудника. Пустая категория — шаблон для любых тикетов.
+
+CREATE TABLE canned_responses (
+    id         BIGSERIAL PRIMARY KEY,
+    title      TEXT         NOT NULL,
+    body       TEXT         NOT NULL,
+    category   VARCHAR(100) NOT NULL DEFAULT '',
+    owner_id   BIGINT,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_canned_responses_category ON canned_responses (category, id);
+CREATE INDEX idx_canned_responses_owner ON canned_responses (owner_id);
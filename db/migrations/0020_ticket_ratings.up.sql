@@ -0,0 +1,14 @@
+-- Оценки закрытых тикетов клиентами (CSAT), не больше одной на тикет.
+-- agent_id и category запоминаются на момент оценки для отчетов.
+
+CREATE TABLE ticket_ratings (
+    ticket_id  INTEGER PRIMARY KEY REFERENCES tickets (id) ON DELETE CASCADE,
+    user_id    BIGINT       NOT NULL,
+    score      SMALLINT     NOT NULL CHECK (score BETWEEN 1 AND 5),
+    comment    TEXT         NOT NULL DEFAULT '',
+    agent_id   BIGINT,
+    category   VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ticket_ratings_created_at ON ticket_ratings (created_at);
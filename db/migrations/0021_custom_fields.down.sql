@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tickets_custom_fields;
+ALTER TABLE tickets DROP COLUMN IF EXISTS custom_fields;
+DROP TABLE IF EXISTS ticket_custom_fields;
@@ -0,0 +1,18 @@
+-- Дополнительные поля тикетов по категориям: схема полей и значения в тикете.
+-- Значения хранятся объектом JSON по ключам полей; GIN-индекс ускоряет фильтр @>.
+
+CREATE TABLE ticket_custom_fields (
+    id         BIGSERIAL PRIMARY KEY,
+    category   VARCHAR(100) NOT NULL,
+    key        VARCHAR(50)  NOT NULL,
+    label      TEXT         NOT NULL,
+    type       VARCHAR(20)  NOT NULL,
+    required   BOOLEAN      NOT NULL DEFAULT FALSE,
+    options    TEXT[]       NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (category, key)
+);
+
+ALTER TABLE tickets ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_tickets_custom_fields ON tickets USING GIN (custom_fields jsonb_path_ops);
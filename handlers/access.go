@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"support_front_api/auth"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentIdentity возвращает личность вызывающего, при ее отсутствии отвечает 401
+func currentIdentity(c *gin.Context) (*auth.Identity, bool) {
+	identity, ok := auth.FromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Требуется авторизация"})
+		return nil, false
+	}
+	return identity, true
+}
+
+// canAccessTicket: сотрудники видят все тикеты, клиенты — только свои
+func canAccessTicket(identity *auth.Identity, ticket *models.Ticket) bool {
+	return identity.IsStaff() || ticket.UserID == identity.UserID
+}
+
+// canAccessUser: сотрудники видят всех пользователей, клиенты — только себя
+func canAccessUser(identity *auth.Identity, userID int64) bool {
+	return identity.IsStaff() || identity.UserID == userID
+}
+
+// loadTicket загружает тикет и проверяет доступ к нему вызывающего.
+// При ошибке ответ уже отправлен, а errorMessage используется для ошибок сервера.
+func (h *Handler) loadTicket(c *gin.Context, identity *auth.Identity, id int, errorMessage string) (*models.Ticket, bool) {
+	ticket, err := h.store.Tickets().GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
+		} else {
+			logger.LogError("Ошибка при проверке тикета: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage})
+		}
+		return nil, false
+	}
+
+	if !canAccessTicket(identity, ticket) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ к тикету запрещен"})
+		return nil, false
+	}
+
+	return ticket, true
+}
+
+// requestError — запрос нельзя выполнить; message возвращается клиенту с кодом status.
+// Возвращается из InTx, чтобы отменить транзакцию.
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// lockTickets загружает и блокирует тикеты внутри InTx. Тикеты блокируются по возрастанию ID,
+// чтобы встречные операции над несколькими тикетами не ждали друг друга; отсутствующий
+// тикет — requestError с кодом 404.
+func lockTickets(ctx context.Context, tx repository.Store, ids []int) (map[int]*models.Ticket, error) {
+	ordered := append([]int(nil), ids...)
+	sort.Ints(ordered)
+
+	tickets := make(map[int]*models.Ticket, len(ordered))
+	for _, id := range ordered {
+		ticket, err := tx.Tickets().GetForUpdate(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, &requestError{http.StatusNotFound, fmt.Sprintf("Тикет %d не найден", id)}
+		}
+		if err != nil {
+			return nil, err
+		}
+		tickets[id] = ticket
+	}
+	return tickets, nil
+}
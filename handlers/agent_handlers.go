@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAgents возвращает список сотрудников поддержки
+func (h *Handler) GetAgents(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Пагинация
+	page, ok := parseListPage(c, 20, "agents", repository.KeyNone)
+	if !ok {
+		return
+	}
+
+	agents, err := h.store.Agents().List(ctx, page.Page)
+	if err != nil {
+		logger.LogError("Ошибка при получении сотрудников: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сотрудников"})
+		return
+	}
+
+	count := 0
+	if !page.cursorMode() {
+		if count, err = h.store.Agents().Count(ctx); err != nil {
+			logger.LogError("Ошибка при подсчете сотрудников: %v", err)
+		}
+	}
+
+	agents, response := finishPage(page, agents, count, func(agent models.Agent) repository.Cursor {
+		return repository.Cursor{ID: agent.ID}
+	})
+	response["agents"] = agents
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAgentById возвращает сотрудника поддержки по ID
+func (h *Handler) GetAgentById(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	agentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID сотрудника"})
+		return
+	}
+
+	agent, err := h.store.Agents().GetByID(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Сотрудник не найден"})
+		} else {
+			logger.LogError("Ошибка при получении сотрудника: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сотрудника"})
+		}
+		return
+	}
+
+	// Тикеты, за которые отвечает сотрудник
+	tickets, err := h.store.Tickets().List(ctx, repository.TicketFilter{AssigneeID: &agentID})
+	if err != nil {
+		logger.LogError("Ошибка при получении тикетов сотрудника: %v", err)
+	}
+	h.presentTicketList(c, tickets)
+
+	c.JSON(http.StatusOK, gin.H{
+		"agent":   agent,
+		"tickets": tickets,
+	})
+}
+
+// CreateAgent добавляет сотрудника поддержки
+func (h *Handler) CreateAgent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request models.NewAgentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exists, err := h.store.Agents().Exists(ctx, request.ID)
+	if err != nil {
+		logger.LogError("Ошибка при проверке существования сотрудника: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании сотрудника"})
+		return
+	}
+
+	if exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Сотрудник с таким ID уже существует"})
+		return
+	}
+
+	agent := models.Agent{
+		ID:        request.ID,
+		FullName:  request.FullName,
+		Email:     request.Email,
+		IsActive:  true,
+		Skills:    request.Skills,
+		CreatedAt: time.Now(),
+	}
+
+	if err := h.store.Agents().Create(ctx, &agent); err != nil {
+		logger.LogError("Ошибка при создании сотрудника: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании сотрудника"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Сотрудник успешно создан",
+		"agent_id": agent.ID,
+	})
+}
+
+// UpdateAgent изменяет данные сотрудника поддержки
+func (h *Handler) UpdateAgent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	agentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID сотрудника"})
+		return
+	}
+
+	var request models.UpdateAgentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	agent, err := h.store.Agents().GetByID(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Сотрудник не найден"})
+		} else {
+			logger.LogError("Ошибка при получении сотрудника: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении сотрудника"})
+		}
+		return
+	}
+
+	wasAvailable := agent.Available()
+
+	// Обновляем только переданные поля
+	if request.FullName != nil {
+		agent.FullName = *request.FullName
+	}
+	if request.Email != nil {
+		agent.Email = *request.Email
+	}
+	if request.IsActive != nil {
+		agent.IsActive = *request.IsActive
+	}
+	if request.Skills != nil {
+		agent.Skills = request.Skills
+	}
+
+	if !h.saveAgent(c, agent, wasAvailable, "Ошибка при обновлении сотрудника") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Сотрудник успешно обновлен",
+		"agent_id": agentID,
+	})
+}
+
+// DeleteAgent удаляет сотрудника поддержки и снимает его с назначенных тикетов
+func (h *Handler) DeleteAgent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	agentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID сотрудника"})
+		return
+	}
+
+	// Нерешенные тикеты передаются другим сотрудникам, если есть кому; с остальных сотрудник снимается;
+	// изменения записываются в историю вместе с удалением сотрудника
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		if _, err := h.router.Reassign(ctx, tx, agentID); err != nil {
+			return err
+		}
+
+		tickets, err := tx.Tickets().List(ctx, repository.TicketFilter{AssigneeID: &agentID})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range tickets {
+			ticket := &tickets[i]
+			ticket.AssigneeID = nil
+			if err := tx.Tickets().Update(ctx, ticket); err != nil {
+				return err
+			}
+			entry := models.NewHistoryEntry(ticket.ID, models.HistoryFieldAssignee, strconv.FormatInt(agentID, 10), "", actorOf(identity), now)
+			if err := tx.History().Add(ctx, entry); err != nil {
+				return err
+			}
+		}
+
+		return tx.Agents().Delete(ctx, agentID)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Сотрудник не найден"})
+		} else {
+			logger.LogError("Ошибка при удалении сотрудника: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении сотрудника"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Сотрудник успешно удален",
+		"agent_id": agentID,
+	})
+}
+
+// SetAgentShift отмечает начало или окончание смены сотрудника.
+// Сотрудник меняет только свою смену, администратор — любую.
+func (h *Handler) SetAgentShift(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	agentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID сотрудника"})
+		return
+	}
+
+	if !identity.IsAdmin() && identity.UserID != agentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Изменять можно только свою смену"})
+		return
+	}
+
+	var request models.AgentShiftRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	agent, err := h.store.Agents().GetByID(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Сотрудник не найден"})
+		} else {
+			logger.LogError("Ошибка при получении сотрудника: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении смены"})
+		}
+		return
+	}
+
+	wasAvailable := agent.Available()
+	agent.OnShift = *request.OnShift
+	if !h.saveAgent(c, agent, wasAvailable, "Ошибка при изменении смены") {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Смена сотрудника обновлена",
+		"agent_id": agentID,
+		"on_shift": agent.OnShift,
+	})
+}
+
+// saveAgent сохраняет сотрудника; если он перестал быть доступен (был доступен до изменения
+// и ушел со смены или деактивирован), его нерешенные тикеты переназначаются в той же транзакции.
+// При ошибке ответ уже отправлен.
+func (h *Handler) saveAgent(c *gin.Context, agent *models.Agent, wasAvailable bool, errorMessage string) bool {
+	ctx := c.Request.Context()
+
+	err := h.store.InTx(ctx, func(tx repository.Store) error {
+		if err := tx.Agents().Update(ctx, agent); err != nil {
+			return err
+		}
+		if !wasAvailable || agent.Available() {
+			return nil
+		}
+
+		moved, err := h.router.Reassign(ctx, tx, agent.ID)
+		if moved > 0 {
+			logger.LogInfo("Тикеты сотрудника %d переназначены: %d", agent.ID, moved)
+		}
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Сотрудник не найден"})
+		} else {
+			logger.LogError("%s: %v", errorMessage, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errorMessage})
+		}
+		return false
+	}
+	return true
+}
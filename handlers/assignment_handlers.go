@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"support_front_api/auth"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssignTicket назначает ответственного за тикет, по умолчанию — вызывающего
+func (h *Handler) AssignTicket(c *gin.Context) {
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	var request models.AssignTicketRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	agentID := identity.UserID
+	if request.AgentID != nil {
+		agentID = *request.AgentID
+	}
+
+	h.setAssignee(c, identity, &agentID)
+}
+
+// UnassignTicket снимает ответственного с тикета
+func (h *Handler) UnassignTicket(c *gin.Context) {
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	h.setAssignee(c, identity, nil)
+}
+
+// setAssignee меняет ответственного за тикет из параметра :id; nil снимает назначение
+func (h *Handler) setAssignee(c *gin.Context, identity *auth.Identity, agentID *int64) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+
+	// Назначить можно только существующего активного сотрудника
+	if agentID != nil {
+		agent, err := h.store.Agents().GetByID(ctx, *agentID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Сотрудник не найден"})
+			} else {
+				logger.LogError("Ошибка при получении сотрудника: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при назначении тикета"})
+			}
+			return
+		}
+		if !agent.IsActive {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Сотрудник неактивен"})
+			return
+		}
+	}
+
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		ticket, err := tx.Tickets().GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		oldValue, newValue := models.AssigneeValue(ticket.AssigneeID), models.AssigneeValue(agentID)
+		if oldValue == newValue {
+			return nil
+		}
+
+		ticket.AssigneeID = agentID
+		if err := tx.Tickets().Update(ctx, ticket); err != nil {
+			return err
+		}
+		return tx.History().Add(ctx, models.NewHistoryEntry(id, models.HistoryFieldAssignee, oldValue, newValue, actorOf(identity), time.Now()))
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
+		} else {
+			logger.LogError("Ошибка при назначении тикета: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при назначении тикета"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Ответственный за тикет обновлен",
+		"ticket_id":   id,
+		"assignee_id": agentID,
+	})
+}
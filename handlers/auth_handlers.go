@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"support_front_api/auth"
+	"support_front_api/config"
+	"support_front_api/logger"
+	"support_front_api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler выдает токены сервисным клиентам
+type AuthHandler struct {
+	issuer  *auth.Issuer
+	clients []config.ServiceClient
+}
+
+// NewAuthHandler создает обработчик выдачи токенов для перечисленных клиентов
+func NewAuthHandler(issuer *auth.Issuer, clients []config.ServiceClient) *AuthHandler {
+	return &AuthHandler{issuer: issuer, clients: clients}
+}
+
+// IssueToken выдает JWT по client_id и client_secret.
+// Клиент с can_impersonate может указать user_id и получить токен клиента этого пользователя.
+func (h *AuthHandler) IssueToken(c *gin.Context) {
+	var request models.TokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client := h.findClient(request.ClientID, request.ClientSecret)
+	if client == nil {
+		logger.LogWarning("Неудачная попытка получения токена клиентом %q", request.ClientID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверные учетные данные клиента"})
+		return
+	}
+
+	identity := auth.Identity{UserID: client.UserID, Role: client.Role}
+	if request.UserID != nil {
+		if !client.CanImpersonate {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Клиенту запрещено получать токены от имени пользователей"})
+			return
+		}
+		identity = auth.Identity{UserID: *request.UserID, Role: auth.RoleCustomer}
+	}
+
+	if !auth.ValidRole(identity.Role) {
+		logger.LogError("У клиента %q указана неизвестная роль %q", client.ClientID, identity.Role)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при выдаче токена"})
+		return
+	}
+
+	token, expiresAt, err := h.issuer.Issue(identity)
+	if err != nil {
+		logger.LogError("Ошибка при выдаче токена: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при выдаче токена"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_at":   expiresAt,
+		"role":         identity.Role,
+	})
+}
+
+// findClient ищет клиента, сравнивая секрет за постоянное время
+func (h *AuthHandler) findClient(clientID, clientSecret string) *config.ServiceClient {
+	for i := range h.clients {
+		client := &h.clients[i]
+		if client.ClientID != clientID {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(client.ClientSecret), []byte(clientSecret)) == 1 {
+			return client
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,452 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"support_front_api/auth"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCannedResponses возвращает шаблоны ответов, доступные сотруднику: общие и его личные.
+// Параметр scope (shared, personal) оставляет только общие или только личные шаблоны,
+// category — шаблоны категории и шаблоны без категории.
+func (h *Handler) GetCannedResponses(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	scope := c.Query("scope")
+	if scope != "" && scope != repository.CannedScopeShared && scope != repository.CannedScopePersonal {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная область шаблонов, допустимы shared и personal"})
+		return
+	}
+
+	// Пагинация
+	page, ok := parseListPage(c, 50, "canned", repository.KeyText)
+	if !ok {
+		return
+	}
+
+	filter := repository.CannedResponseFilter{
+		VisibleTo: identity.UserID,
+		Scope:     scope,
+		Category:  models.NormalizeCategory(c.Query("category")),
+		Page:      page.Page,
+	}
+
+	responses, err := h.store.CannedResponses().List(ctx, filter)
+	if err != nil {
+		logger.LogError("Ошибка при получении шаблонов ответов: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении шаблонов ответов"})
+		return
+	}
+
+	count := 0
+	if !page.cursorMode() {
+		if count, err = h.store.CannedResponses().Count(ctx, filter); err != nil {
+			logger.LogError("Ошибка при подсчете шаблонов ответов: %v", err)
+		}
+	}
+
+	responses, response := finishPage(page, responses, count, func(canned models.CannedResponse) repository.Cursor {
+		return repository.Cursor{Key: canned.Category, ID: canned.ID}
+	})
+	response["canned_responses"] = responses
+	response["variables"] = models.TemplateVariables
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCannedResponse добавляет шаблон ответа: личный шаблон автора или, для администратора, общий
+func (h *Handler) CreateCannedResponse(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	var request models.NewCannedResponseRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.Shared && !identity.IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Общие шаблоны может создавать только администратор"})
+		return
+	}
+	if !validTemplate(c, request.Body) {
+		return
+	}
+
+	now := time.Now()
+	canned := models.CannedResponse{
+		Title:     request.Title,
+		Body:      request.Body,
+		Category:  models.NormalizeCategory(request.Category),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if !request.Shared {
+		canned.OwnerID = &identity.UserID
+	}
+
+	if err := h.store.CannedResponses().Create(ctx, &canned); err != nil {
+		logger.LogError("Ошибка при создании шаблона ответа: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании шаблона ответа"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         "Шаблон ответа создан",
+		"canned_response": canned,
+	})
+}
+
+// UpdateCannedResponse изменяет шаблон ответа; личный шаблон меняет автор, общий — администратор
+func (h *Handler) UpdateCannedResponse(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	var request models.UpdateCannedResponseRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	canned, ok := h.loadCannedResponse(c, identity, "Ошибка при обновлении шаблона ответа")
+	if !ok || !canEditCanned(c, identity, canned) {
+		return
+	}
+
+	// Обновляем только переданные поля
+	if request.Title != nil {
+		canned.Title = *request.Title
+	}
+	if request.Body != nil {
+		if !validTemplate(c, *request.Body) {
+			return
+		}
+		canned.Body = *request.Body
+	}
+	if request.Category != nil {
+		canned.Category = models.NormalizeCategory(*request.Category)
+	}
+	if canned.Title == "" || canned.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Заголовок и текст шаблона не могут быть пустыми"})
+		return
+	}
+	canned.UpdatedAt = time.Now()
+
+	if err := h.store.CannedResponses().Update(ctx, canned); err != nil {
+		logger.LogError("Ошибка при обновлении шаблона ответа: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении шаблона ответа"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Шаблон ответа обновлен",
+		"canned_response": canned,
+	})
+}
+
+// DeleteCannedResponse удаляет шаблон ответа; личный шаблон удаляет автор, общий — администратор
+func (h *Handler) DeleteCannedResponse(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	canned, ok := h.loadCannedResponse(c, identity, "Ошибка при удалении шаблона ответа")
+	if !ok || !canEditCanned(c, identity, canned) {
+		return
+	}
+
+	if err := h.store.CannedResponses().Delete(ctx, canned.ID); err != nil {
+		logger.LogError("Ошибка при удалении шаблона ответа: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении шаблона ответа"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Шаблон ответа удален"})
+}
+
+// ApplyMacro отвечает клиенту текстом шаблона с подставленными переменными и, если переданы
+// status и category, меняет статус и категорию тикета. Ответ, изменения тикета, история
+// и уведомления сохраняются в одной транзакции.
+func (h *Handler) ApplyMacro(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	ticketID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+
+	var request models.MacroRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Статус принимается кодом или подписью, как в UpdateTicket
+	if request.Status != "" {
+		status, ok := models.ParseStatus(request.Status)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":    "Неизвестный статус тикета",
+				"statuses": models.Statuses,
+			})
+			return
+		}
+		request.Status = status
+	}
+	request.Category = models.NormalizeCategory(request.Category)
+
+	ticket, ok := h.loadTicket(c, identity, ticketID, "Ошибка при применении макроса")
+	if !ok {
+		return
+	}
+
+	canned, err := h.store.CannedResponses().GetByID(ctx, request.CannedResponseID)
+	if err == nil && !cannedVisible(identity, canned) {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Шаблон ответа не найден"})
+		} else {
+			logger.LogError("Ошибка при получении шаблона ответа: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при применении макроса"})
+		}
+		return
+	}
+
+	values, err := h.templateValues(ctx, ticket, identity)
+	if err != nil {
+		logger.LogError("Ошибка при подготовке переменных шаблона: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при применении макроса"})
+		return
+	}
+
+	message := models.TicketMessage{
+		TicketID:   ticketID,
+		SenderType: identity.SenderType(),
+		SenderID:   identity.UserID,
+		Kind:       models.MessageKindReply,
+		CreatedAt:  time.Now(),
+	}
+
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		locked, err := tx.Tickets().GetForUpdate(ctx, ticketID)
+		if err != nil {
+			return err
+		}
+		if locked.MergedIntoID != nil {
+			return &requestError{http.StatusBadRequest, fmt.Sprintf("Тикет объединен с тикетом %d", *locked.MergedIntoID)}
+		}
+		if models.IsFinalStatus(locked.Status) {
+			return errTicketClosed
+		}
+
+		now := message.CreatedAt
+		actor := actorOf(identity)
+		var changes []models.TicketHistoryEntry
+
+		statusChanged, resolved := false, false
+		if request.Status != "" && request.Status != locked.Status {
+			oldStatus := locked.Status
+			if err := locked.ApplyStatus(request.Status, now); err != nil {
+				return err
+			}
+			changes = append(changes, models.NewHistoryEntry(ticketID, models.HistoryFieldStatus, oldStatus, locked.Status, actor, now))
+			statusChanged = true
+			resolved = models.IsActiveStatus(oldStatus) && !models.IsActiveStatus(locked.Status)
+		}
+		if request.Category != "" && request.Category != locked.Category {
+			changes = append(changes, models.NewHistoryEntry(ticketID, models.HistoryFieldCategory, locked.Category, request.Category, actor, now))
+			locked.Category = request.Category
+			if err := checkCategoryFields(ctx, tx, locked); err != nil {
+				return err
+			}
+			// Сроки SLA зависят от категории
+			h.sla.Apply(locked)
+		}
+
+		// Категория в тексте — уже с учетом изменения макросом
+		values[models.TemplateTicketCategory] = models.CategoryLabel(locked.Category, models.LangRU)
+		message.Message = models.RenderTemplate(canned.Body, values)
+
+		if err := tx.Messages().Create(ctx, &message); err != nil {
+			return err
+		}
+		changes = append([]models.TicketHistoryEntry{
+			models.NewHistoryEntry(ticketID, models.HistoryFieldMessage, "", strconv.Itoa(message.ID), actor, now),
+		}, changes...)
+
+		// Ответ поддержки выполняет срок первого ответа SLA и запускает ожидание клиента
+		if locked.FirstResponseAt == nil {
+			locked.FirstResponseAt = &message.CreatedAt
+		}
+		locked.TrackReply(true, now)
+		if err := tx.Tickets().Update(ctx, locked); err != nil {
+			return err
+		}
+		if err := tx.History().Add(ctx, changes...); err != nil {
+			return err
+		}
+		if resolved {
+			if err := h.resolveChildren(ctx, tx, locked, actor, now); err != nil {
+				return err
+			}
+		}
+
+		err = h.outbox.Enqueue(ctx, tx, notify.Notification{
+			Event:       notify.EventMessageAdded,
+			TicketID:    ticketID,
+			RecipientID: locked.UserID,
+			Message:     fmt.Sprintf("В вашем тикете %d новое сообщение: %s", ticketID, message.Message),
+		})
+		if err != nil || !statusChanged {
+			return err
+		}
+		return h.outbox.Enqueue(ctx, tx, notify.Notification{
+			Event:       notify.EventTicketUpdated,
+			TicketID:    ticketID,
+			RecipientID: locked.UserID,
+			Message:     notify.StatusNotification(ticketID, locked.Status, ""),
+		})
+	})
+	if err != nil {
+		var transitionErr *models.TransitionError
+		var reqErr *requestError
+		var fieldsErr *customFieldsError
+		switch {
+		case errors.As(err, &reqErr):
+			c.JSON(reqErr.status, gin.H{"error": reqErr.message})
+		case errors.As(err, &fieldsErr):
+			respondCustomFieldsError(c, fieldsErr)
+		case errors.Is(err, errTicketClosed):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя добавить сообщение в закрытый тикет"})
+		case errors.As(err, &transitionErr):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":            transitionErr.Error(),
+				"allowed_statuses": models.AllowedTransitions(transitionErr.From),
+			})
+		default:
+			logger.LogError("Ошибка при применении макроса: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при применении макроса"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Макрос применен",
+		"message_id": message.ID,
+		"text":       message.Message,
+	})
+}
+
+// templateValues собирает значения переменных шаблона для тикета. Если клиент или
+// сотрудник не найдены, их имена подставляются пустыми.
+func (h *Handler) templateValues(ctx context.Context, ticket *models.Ticket, identity *auth.Identity) (map[string]string, error) {
+	values := map[string]string{
+		models.TemplateCustomerName:   "",
+		models.TemplateTicketID:       strconv.Itoa(ticket.ID),
+		models.TemplateTicketTitle:    ticket.Title,
+		models.TemplateTicketCategory: models.CategoryLabel(ticket.Category, models.LangRU),
+		models.TemplateAgentName:      "",
+	}
+
+	user, err := h.store.Users().GetByID(ctx, ticket.UserID)
+	switch {
+	case err == nil:
+		values[models.TemplateCustomerName] = user.FullName
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	agent, err := h.store.Agents().GetByID(ctx, identity.UserID)
+	switch {
+	case err == nil:
+		values[models.TemplateAgentName] = agent.FullName
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// loadCannedResponse загружает шаблон из параметра id; чужой личный шаблон считается ненайденным
+func (h *Handler) loadCannedResponse(c *gin.Context, identity *auth.Identity, errMsg string) (*models.CannedResponse, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID шаблона ответа"})
+		return nil, false
+	}
+
+	canned, err := h.store.CannedResponses().GetByID(c.Request.Context(), id)
+	if err == nil && !cannedVisible(identity, canned) {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Шаблон ответа не найден"})
+		} else {
+			logger.LogError("%s: %v", errMsg, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		}
+		return nil, false
+	}
+	return canned, true
+}
+
+// cannedVisible сообщает, что сотрудник видит шаблон: общий или свой личный
+func cannedVisible(identity *auth.Identity, canned *models.CannedResponse) bool {
+	return canned.Shared() || *canned.OwnerID == identity.UserID
+}
+
+// canEditCanned проверяет право изменять шаблон и при отказе отвечает 403
+func canEditCanned(c *gin.Context, identity *auth.Identity, canned *models.CannedResponse) bool {
+	if canned.Shared() && !identity.IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Общие шаблоны может изменять только администратор"})
+		return false
+	}
+	return true
+}
+
+// validTemplate проверяет, что в тексте шаблона только известные переменные, иначе отвечает 400
+func validTemplate(c *gin.Context, body string) bool {
+	if unknown := models.UnknownTemplateVariables(body); len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     fmt.Sprintf("Неизвестные переменные шаблона: %v", unknown),
+			"unknown":   unknown,
+			"variables": models.TemplateVariables,
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFieldOptions ограничивает число вариантов поля типа enum
+const maxFieldOptions = 50
+
+// GetCustomFields возвращает схему дополнительных полей категории (category) или всех категорий.
+// Схема нужна и клиентам, чтобы показать поля в форме нового тикета.
+func (h *Handler) GetCustomFields(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	fields, err := h.store.CustomFields().List(ctx, models.NormalizeCategory(c.Query("category")))
+	if err != nil {
+		logger.LogError("Ошибка при получении дополнительных полей: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении дополнительных полей"})
+		return
+	}
+	if fields == nil {
+		fields = []models.CustomField{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"custom_fields": fields,
+		"types":         models.FieldTypes,
+	})
+}
+
+// CreateCustomField добавляет поле в схему категории
+func (h *Handler) CreateCustomField(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var request models.NewCustomFieldRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !models.ValidFieldKey(request.Key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ключ поля: латинские буквы в нижнем регистре, цифры и _, не длиннее 50 символов, с буквы"})
+		return
+	}
+	if !models.ValidFieldType(request.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Неизвестный тип поля",
+			"types": models.FieldTypes,
+		})
+		return
+	}
+	options, ok := fieldOptions(c, request.Type, request.Options)
+	if !ok {
+		return
+	}
+
+	field := models.CustomField{
+		Category:  models.NormalizeCategory(request.Category),
+		Key:       request.Key,
+		Label:     strings.TrimSpace(request.Label),
+		Type:      request.Type,
+		Required:  request.Required,
+		Options:   options,
+		CreatedAt: time.Now(),
+	}
+	if field.Category == "" || field.Label == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Категория и подпись поля не могут быть пустыми"})
+		return
+	}
+
+	created, err := h.store.CustomFields().Create(ctx, &field)
+	if err != nil {
+		logger.LogError("Ошибка при создании дополнительного поля: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании дополнительного поля"})
+		return
+	}
+	if !created {
+		c.JSON(http.StatusConflict, gin.H{"error": "В категории уже есть поле с таким ключом"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Дополнительное поле создано",
+		"custom_field": field,
+	})
+}
+
+// UpdateCustomField изменяет подпись, обязательность и варианты поля. Новые правила
+// действуют на тикеты, создаваемые после изменения.
+func (h *Handler) UpdateCustomField(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID поля"})
+		return
+	}
+
+	var request models.UpdateCustomFieldRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	field, err := h.store.CustomFields().GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Дополнительное поле не найдено"})
+		} else {
+			logger.LogError("Ошибка при получении дополнительного поля: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении дополнительного поля"})
+		}
+		return
+	}
+
+	// Обновляем только переданные поля
+	if request.Label != nil {
+		if field.Label = strings.TrimSpace(*request.Label); field.Label == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Подпись поля не может быть пустой"})
+			return
+		}
+	}
+	if request.Required != nil {
+		field.Required = *request.Required
+	}
+	if request.Options != nil {
+		options, ok := fieldOptions(c, field.Type, request.Options)
+		if !ok {
+			return
+		}
+		field.Options = options
+	}
+
+	if err := h.store.CustomFields().Update(ctx, field); err != nil {
+		logger.LogError("Ошибка при обновлении дополнительного поля: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении дополнительного поля"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Дополнительное поле обновлено",
+		"custom_field": field,
+	})
+}
+
+// DeleteCustomField удаляет поле из схемы; значения в существующих тикетах сохраняются
+func (h *Handler) DeleteCustomField(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID поля"})
+		return
+	}
+
+	if err := h.store.CustomFields().Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Дополнительное поле не найдено"})
+		} else {
+			logger.LogError("Ошибка при удалении дополнительного поля: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении дополнительного поля"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Дополнительное поле удалено"})
+}
+
+// customFieldsError — сохраненные значения дополнительных полей не подходят к схеме новой категории тикета
+type customFieldsError struct {
+	fields map[string]string
+}
+
+func (e *customFieldsError) Error() string {
+	return "дополнительные поля не соответствуют схеме категории"
+}
+
+// checkCategoryFields проверяет сохраненные значения дополнительных полей тикета по схеме его
+// текущей категории. Вызывается при смене категории внутри InTx; при несоответствии
+// возвращает customFieldsError.
+func checkCategoryFields(ctx context.Context, tx repository.Store, ticket *models.Ticket) error {
+	fields, err := tx.CustomFields().List(ctx, ticket.Category)
+	if err != nil {
+		return err
+	}
+	if _, fieldErrors := models.ValidateCustomFields(fields, ticket.CustomFields); len(fieldErrors) > 0 {
+		return &customFieldsError{fields: fieldErrors}
+	}
+	return nil
+}
+
+// respondCustomFieldsError отвечает 400 со списком неверных полей
+func respondCustomFieldsError(c *gin.Context, err *customFieldsError) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":  "Дополнительные поля тикета не подходят к новой категории",
+		"fields": err.fields,
+	})
+}
+
+// fieldOptions проверяет варианты поля: они обязательны для enum и запрещены для других типов.
+// При ошибке отвечает 400.
+func fieldOptions(c *gin.Context, fieldType string, options []string) ([]string, bool) {
+	if fieldType != models.FieldTypeEnum {
+		if len(options) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Варианты задаются только для поля типа enum"})
+			return nil, false
+		}
+		return nil, true
+	}
+
+	var cleaned []string
+	seen := make(map[string]bool)
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		if seen[option] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Вариант %q указан дважды", option)})
+			return nil, false
+		}
+		seen[option] = true
+		cleaned = append(cleaned, option)
+	}
+	if len(cleaned) == 0 || len(cleaned) > maxFieldOptions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Для поля типа enum нужно от 1 до %d вариантов", maxFieldOptions)})
+		return nil, false
+	}
+	return cleaned, true
+}
+
+// normalizeFieldFilter приводит значения фильтра по дополнительным полям к типам полей.
+// Поле ищется в схеме категорий фильтра, а без фильтра по категории — во всех категориях.
+// Если в этих категориях у поля разные типы, фильтр неоднозначен и отклоняется.
+func (h *Handler) normalizeFieldFilter(ctx context.Context, filter *repository.TicketFilter) (*filterError, error) {
+	if len(filter.CustomFields) == 0 {
+		return nil, nil
+	}
+
+	fields, err := h.store.CustomFields().List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string]bool, len(filter.Categories))
+	for _, category := range filter.Categories {
+		categories[category] = true
+	}
+
+	for key, raw := range filter.CustomFields {
+		var matched []models.CustomField
+		for _, field := range fields {
+			inCategory := len(categories) == 0 || categories[field.Category]
+			if field.Key == key && inCategory {
+				matched = append(matched, field)
+			}
+		}
+		if len(matched) == 0 {
+			return &filterError{message: fmt.Sprintf("Неизвестное дополнительное поле %s", key)}, nil
+		}
+		for _, field := range matched[1:] {
+			if field.Type != matched[0].Type {
+				return &filterError{message: fmt.Sprintf("Поле %s имеет разные типы в разных категориях, укажите категорию (category)", key)}, nil
+			}
+		}
+
+		// У поля enum варианты в категориях могут различаться: достаточно, чтобы значение подошло одной из них
+		var value interface{}
+		var normalizeErr error
+		for i := range matched {
+			if value, normalizeErr = matched[i].Normalize(raw); normalizeErr == nil {
+				break
+			}
+		}
+		if normalizeErr != nil {
+			return &filterError{message: fmt.Sprintf("Неверное значение поля %s: %v", key, normalizeErr)}, nil
+		}
+		filter.CustomFields[key] = value
+	}
+	return nil, nil
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"support_front_api/notify"
+	"support_front_api/repository"
+	"support_front_api/routing"
+	"support_front_api/sla"
+)
+
+// Handler содержит зависимости HTTP-обработчиков
+type Handler struct {
+	store  repository.Store
+	outbox *notify.Outbox
+	router *routing.Router
+	sla    *sla.Policies
+}
+
+// NewHandler создает обработчики, работающие с указанным хранилищем,
+// очередью уведомлений, маршрутизатором тикетов и политиками SLA
+func NewHandler(store repository.Store, outbox *notify.Outbox, router *routing.Router, slaPolicies *sla.Policies) *Handler {
+	return &Handler{store: store, outbox: outbox, router: router, sla: slaPolicies}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"support_front_api/auth"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actorOf возвращает автора изменений для записи в историю тикета
+func actorOf(identity *auth.Identity) models.Actor {
+	id := identity.UserID
+	return models.Actor{Role: identity.Role, ID: &id}
+}
+
+// GetTicketHistory возвращает историю изменений тикета
+func (h *Handler) GetTicketHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+
+	// Пагинация
+	page, ok := parseListPage(c, 50, "history", repository.KeyTime)
+	if !ok {
+		return
+	}
+
+	if _, ok := h.loadTicket(c, identity, id, "Ошибка при получении истории тикета"); !ok {
+		return
+	}
+
+	entries, err := h.store.History().ListByTicket(ctx, id, page.Page)
+	if err != nil {
+		logger.LogError("Ошибка при получении истории тикета: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении истории тикета"})
+		return
+	}
+
+	count := 0
+	if !page.cursorMode() {
+		if count, err = h.store.History().CountByTicket(ctx, id); err != nil {
+			logger.LogError("Ошибка при подсчете истории тикета: %v", err)
+		}
+	}
+
+	entries, response := finishPage(page, entries, count, func(entry models.TicketHistoryEntry) repository.Cursor {
+		return repository.Cursor{Key: repository.TimeKey(&entry.CreatedAt), ID: entry.ID}
+	})
+
+	lang := requestLang(c)
+	for i := range entries {
+		entries[i].Localize(lang)
+	}
+
+	response["history"] = entries
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"support_front_api/auth"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTicketLink связывает тикет из пути запроса с тикетом ticket_id
+func (h *Handler) CreateTicketLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+
+	var request models.NewTicketLinkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.ValidLinkType(request.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Неизвестный тип связи",
+			"link_types": models.LinkTypes,
+		})
+		return
+	}
+	if request.TicketID == id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя связать тикет с самим собой"})
+		return
+	}
+
+	// Действие с дочерним тикетом имеет смысл только для связи родитель — дочерний тикет
+	hierarchy := request.Type == models.LinkParentOf || request.Type == models.LinkChildOf
+	if !hierarchy && request.OnParentResolved != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "on_parent_resolved задается только для связей parent_of и child_of"})
+		return
+	}
+	if hierarchy && request.OnParentResolved == "" {
+		request.OnParentResolved = models.ChildActionNotify
+	}
+	if hierarchy && !models.ValidChildAction(request.OnParentResolved) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         "Неизвестное действие с дочерним тикетом",
+			"child_actions": models.ChildActions,
+		})
+		return
+	}
+
+	link := models.TicketLink{
+		TicketID:         id,
+		LinkedTicketID:   request.TicketID,
+		Type:             request.Type,
+		OnParentResolved: request.OnParentResolved,
+		CreatedAt:        time.Now(),
+	}.Canonical()
+
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		if _, err := lockTickets(ctx, tx, []int{link.TicketID, link.LinkedTicketID}); err != nil {
+			return err
+		}
+		if link.Type == models.LinkParentOf {
+			if err := checkHierarchy(ctx, tx, link.TicketID, link.LinkedTicketID); err != nil {
+				return err
+			}
+		}
+
+		created, err := tx.Links().Create(ctx, &link)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return &requestError{http.StatusConflict, fmt.Sprintf("Тикеты %d и %d уже связаны", id, request.TicketID)}
+		}
+
+		actor := actorOf(identity)
+		reverse := link.From(link.LinkedTicketID)
+		return tx.History().Add(ctx,
+			models.NewHistoryEntry(link.TicketID, models.HistoryFieldLink, "", link.HistoryValue(), actor, link.CreatedAt),
+			models.NewHistoryEntry(reverse.TicketID, models.HistoryFieldLink, "", reverse.HistoryValue(), actor, link.CreatedAt),
+		)
+	})
+	if err != nil {
+		var reqErr *requestError
+		if errors.As(err, &reqErr) {
+			c.JSON(reqErr.status, gin.H{"error": reqErr.message})
+		} else {
+			logger.LogError("Ошибка при создании связи тикетов: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании связи"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Связь создана",
+		"link":    link.From(id),
+	})
+}
+
+// DeleteTicketLink удаляет связь тикета из пути запроса
+func (h *Handler) DeleteTicketLink(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+	linkID, err := strconv.ParseInt(c.Param("link_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID связи"})
+		return
+	}
+
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		link, err := tx.Links().GetByID(ctx, linkID)
+		if err != nil {
+			return err
+		}
+		if link.TicketID != id && link.LinkedTicketID != id {
+			return repository.ErrNotFound
+		}
+		if err := tx.Links().Delete(ctx, linkID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		actor := actorOf(identity)
+		reverse := link.From(link.LinkedTicketID)
+		return tx.History().Add(ctx,
+			models.NewHistoryEntry(link.TicketID, models.HistoryFieldLink, link.HistoryValue(), "", actor, now),
+			models.NewHistoryEntry(reverse.TicketID, models.HistoryFieldLink, reverse.HistoryValue(), "", actor, now),
+		)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Связь не найдена"})
+		} else {
+			logger.LogError("Ошибка при удалении связи тикетов: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении связи"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Связь удалена",
+		"link_id": linkID,
+	})
+}
+
+// checkHierarchy проверяет, что тикет childID можно сделать дочерним для parentID:
+// у него еще нет родительского тикета, и он не является предком parentID
+func checkHierarchy(ctx context.Context, tx repository.Store, parentID, childID int) error {
+	current, ok, err := parentOf(ctx, tx, childID)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return &requestError{http.StatusConflict, fmt.Sprintf("У тикета %d уже есть родительский тикет %d", childID, current)}
+	}
+
+	// Цепочка родителей конечна: циклы не создаются этой же проверкой
+	for ancestor := parentID; ; {
+		ancestor, ok, err = parentOf(ctx, tx, ancestor)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if ancestor == childID {
+			return &requestError{http.StatusBadRequest, fmt.Sprintf("Тикет %d входит в цепочку родительских тикетов %d", childID, parentID)}
+		}
+	}
+}
+
+// parentOf возвращает ID родительского тикета
+func parentOf(ctx context.Context, tx repository.Store, ticketID int) (int, bool, error) {
+	links, err := tx.Links().ListByTicket(ctx, ticketID)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, link := range links {
+		if link.Type == models.LinkParentOf && link.LinkedTicketID == ticketID {
+			return link.TicketID, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// linkedTickets возвращает связи тикета для его карточки. Клиент видит только связи
+// со своими тикетами; тикеты из корзины пропускаются.
+func (h *Handler) linkedTickets(c *gin.Context, identity *auth.Identity, ticketID int) ([]models.LinkedTicket, error) {
+	ctx := c.Request.Context()
+
+	links, err := h.store.Links().ListByTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	lang := requestLang(c)
+	result := make([]models.LinkedTicket, 0, len(links))
+	for _, link := range links {
+		link = link.From(ticketID)
+		other, err := h.store.Tickets().GetByID(ctx, link.LinkedTicketID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !canAccessTicket(identity, other) {
+			continue
+		}
+
+		result = append(result, models.LinkedTicket{
+			LinkID:           link.ID,
+			Type:             link.Type,
+			TicketID:         other.ID,
+			Title:            other.Title,
+			Status:           other.Status,
+			StatusLabel:      models.StatusLabel(other.Status, lang),
+			OnParentResolved: link.OnParentResolved,
+		})
+	}
+	return result, nil
+}
+
+// resolveChildren выполняет действия с нерешенными дочерними тикетами решенного или закрытого
+// тикета parent: уведомляет их авторов, а при действии close закрывает и сами тикеты вместе
+// с их дочерними. Вызывается внутри InTx.
+func (h *Handler) resolveChildren(ctx context.Context, tx repository.Store, parent *models.Ticket, actor models.Actor, now time.Time) error {
+	links, err := tx.Links().ListByTicket(ctx, parent.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		if link.Type != models.LinkParentOf || link.TicketID != parent.ID {
+			continue
+		}
+		child, err := tx.Tickets().GetForUpdate(ctx, link.LinkedTicketID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !models.IsActiveStatus(child.Status) {
+			continue
+		}
+
+		message := fmt.Sprintf("Проблема по вашему тикету %d решена в тикете %d", child.ID, parent.ID)
+		if link.OnParentResolved == models.ChildActionClose {
+			oldStatus := child.Status
+			if err := child.ApplyStatus(models.StatusClosed, now); err != nil {
+				return err
+			}
+			if err := tx.Tickets().Update(ctx, child); err != nil {
+				return err
+			}
+			entry := models.NewHistoryEntry(child.ID, models.HistoryFieldStatus, oldStatus, child.Status, actor, now)
+			if err := tx.History().Add(ctx, entry); err != nil {
+				return err
+			}
+			if err := h.resolveChildren(ctx, tx, child, actor, now); err != nil {
+				return err
+			}
+			message = notify.StatusNotification(child.ID, child.Status, fmt.Sprintf("проблема решена в тикете %d", parent.ID))
+		}
+
+		err = h.outbox.Enqueue(ctx, tx, notify.Notification{
+			Event:       notify.EventTicketUpdated,
+			TicketID:    child.ID,
+			RecipientID: child.UserID,
+			Message:     message,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
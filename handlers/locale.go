@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"support_front_api/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// langMatcher подбирает язык подписей по Accept-Language; порядок совпадает с models.Languages
+var langMatcher = language.NewMatcher([]language.Tag{language.Russian, language.English})
+
+// requestLang возвращает язык подписей для запроса, по умолчанию models.DefaultLang
+func requestLang(c *gin.Context) string {
+	header := c.GetHeader("Accept-Language")
+	if header == "" {
+		return models.DefaultLang
+	}
+
+	_, index := language.MatchStrings(langMatcher, header)
+	return models.Languages[index]
+}
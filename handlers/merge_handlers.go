@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMergeSources ограничивает число тикетов, объединяемых за один запрос
+const maxMergeSources = 10
+
+// MergeTickets объединяет тикеты-дубликаты с тикетом из пути запроса: сообщения и фотографии
+// переносятся в целевой тикет, дубликаты закрываются со ссылкой на него. Сроки SLA
+// целевого тикета не меняются.
+func (h *Handler) MergeTickets(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+
+	var request models.MergeTicketsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.SourceIDs) == 0 || len(request.SourceIDs) > maxMergeSources {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Укажите от 1 до %d тикетов для объединения", maxMergeSources)})
+		return
+	}
+
+	ids := []int{targetID}
+	seen := map[int]bool{targetID: true}
+	for _, id := range request.SourceIDs {
+		if seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Тикет %d указан несколько раз или совпадает с целевым", id)})
+			return
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	var messagesMoved, photosMoved int
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		tickets, err := lockTickets(ctx, tx, ids)
+		if err != nil {
+			return err
+		}
+
+		target := tickets[targetID]
+		if target.MergedIntoID != nil {
+			return &requestError{http.StatusBadRequest, fmt.Sprintf("Тикет %d уже объединен с тикетом %d", target.ID, *target.MergedIntoID)}
+		}
+		if target.Status == models.StatusClosed {
+			return &requestError{http.StatusBadRequest, "Нельзя объединить тикеты с закрытым тикетом"}
+		}
+		for _, id := range request.SourceIDs {
+			source := tickets[id]
+			if source.UserID != target.UserID {
+				return &requestError{http.StatusBadRequest, fmt.Sprintf("Тикет %d создан другим клиентом", id)}
+			}
+			if source.MergedIntoID != nil {
+				return &requestError{http.StatusBadRequest, fmt.Sprintf("Тикет %d уже объединен с тикетом %d", id, *source.MergedIntoID)}
+			}
+		}
+
+		now := time.Now()
+		actor := actorOf(identity)
+		var changes []models.TicketHistoryEntry
+
+		for _, id := range request.SourceIDs {
+			source := tickets[id]
+
+			// Списки упорядочены по времени создания, поэтому перенесенная переписка
+			// встает в хронологическом порядке среди сообщений целевого тикета
+			n, err := tx.Messages().MoveToTicket(ctx, id, targetID)
+			if err != nil {
+				return err
+			}
+			messagesMoved += n
+			if n, err = tx.Photos().MoveToTicket(ctx, id, targetID); err != nil {
+				return err
+			}
+			photosMoved += n
+
+			oldStatus := source.Status
+			if err := source.ApplyStatus(models.StatusClosed, now); err != nil {
+				return err
+			}
+			// Дубликат не переоткрывается сообщением клиента и не ждет его ответа
+			source.MergedIntoID = &target.ID
+			source.AutoClosedAt = nil
+			source.WaitingSince = nil
+			source.StaleRemindedAt = nil
+			if err := tx.Tickets().Update(ctx, source); err != nil {
+				return err
+			}
+
+			if oldStatus != source.Status {
+				changes = append(changes, models.NewHistoryEntry(id, models.HistoryFieldStatus, oldStatus, source.Status, actor, now))
+			}
+			changes = append(changes,
+				models.NewHistoryEntry(id, models.HistoryFieldMergedInto, "", strconv.Itoa(targetID), actor, now),
+				models.NewHistoryEntry(targetID, models.HistoryFieldMerged, "", strconv.Itoa(id), actor, now),
+			)
+
+			err = h.outbox.Enqueue(ctx, tx, notify.Notification{
+				Event:       notify.EventTicketUpdated,
+				TicketID:    id,
+				RecipientID: source.UserID,
+				Message:     fmt.Sprintf("Ваш тикет %d объединен с тикетом %d, переписка продолжится в нем", id, targetID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.History().Add(ctx, changes...)
+	})
+	if err != nil {
+		var reqErr *requestError
+		if errors.As(err, &reqErr) {
+			c.JSON(reqErr.status, gin.H{"error": reqErr.message})
+		} else {
+			logger.LogError("Ошибка при объединении тикетов: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при объединении тикетов"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Тикеты объединены",
+		"ticket_id":      targetID,
+		"merged_ids":     request.SourceIDs,
+		"messages_moved": messagesMoved,
+		"photos_moved":   photosMoved,
+	})
+}
@@ -1,20 +1,31 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
 	"strconv"
-	"support_front_api/db"
 	"support_front_api/logger"
 	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// errTicketClosed — тикет закрыли, пока добавлялось сообщение
+var errTicketClosed = errors.New("тикет закрыт")
+
 // AddMessage добавляет новое сообщение к тикету
-func AddMessage(c *gin.Context) {
+func (h *Handler) AddMessage(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
 	ticketID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
@@ -27,141 +38,150 @@ func AddMessage(c *gin.Context) {
 		return
 	}
 
-	// Проверяем существование тикета
-	var exists bool
-	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM tickets WHERE id = $1)", ticketID).Scan(&exists)
-	if err != nil {
-		logger.LogError("Ошибка при проверке тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении сообщения"})
+	// Проверяем существование тикета и доступ к нему
+	ticket, ok := h.loadTicket(c, identity, ticketID, "Ошибка при добавлении сообщения")
+	if !ok {
 		return
 	}
 
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
-		return
-	}
-
-	// Проверяем, что тикет не закрыт
-	var status string
-	var ticekt_user_id int
-	err = db.DB.QueryRow("SELECT status, user_id FROM tickets WHERE id = $1", ticketID).Scan(&status, &ticekt_user_id)
-	if err != nil {
-		logger.LogError("Ошибка при получении статуса тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении сообщения"})
+	// Переписка объединенного тикета продолжается в тикете, с которым его объединили
+	if ticket.MergedIntoID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":          fmt.Sprintf("Тикет объединен с тикетом %d, напишите в него", *ticket.MergedIntoID),
+			"merged_into_id": *ticket.MergedIntoID,
+		})
 		return
 	}
 
-	if status == "закрыт" {
+	// Проверяем, что тикет не закрыт. Клиент может написать в тикет, закрытый
+	// автоматически из-за его молчания, — тогда тикет переоткрывается.
+	reopen := !identity.IsStaff() && ticket.AutoClosedAt != nil
+	if models.IsFinalStatus(ticket.Status) && !reopen {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя добавить сообщение в закрытый тикет"})
 		return
 	}
 
-	// Добавляем сообщение
-	var messageID int
-	err = db.DB.QueryRow(
-		"INSERT INTO ticket_messages (ticket_id, sender_type, sender_id, message, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id",
-		ticketID, request.SenderType, request.SenderID, request.Message, time.Now(),
-	).Scan(&messageID)
-
-	if err != nil {
-		logger.LogError("Ошибка при добавлении сообщения: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении сообщения"})
-		return
+	// Добавляем сообщение, отправитель определяется по токену
+	message := models.TicketMessage{
+		TicketID:   ticketID,
+		SenderType: identity.SenderType(),
+		SenderID:   identity.UserID,
+		Kind:       models.MessageKindReply,
+		Message:    request.Message,
+		CreatedAt:  time.Now(),
 	}
 
-	// Отправляем уведомление на localhost/superconnect
-	go func() {
-		superconnectURL := fmt.Sprintf("http://localhost/superconnect?super_connect_token=super_secret_key_2024&sender_id=5259653323&message=По вашему тикету %d пришло новое сообщение&accepter_id=5259653319", ticketID)
-
-		resp, err := http.Post(superconnectURL, "application/x-www-form-urlencoded", nil)
+	// Сообщение, изменения тикета, история и уведомление сохраняются в одной транзакции
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		locked, err := tx.Tickets().GetForUpdate(ctx, ticketID)
 		if err != nil {
-			logger.LogError("Ошибка при отправке уведомления: %v", err)
-			return
+			return err
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			logger.LogError("Ошибка при отправке уведомления, код ответа: %d", resp.StatusCode)
+		reopened := false
+		if reopen && locked.Status == models.StatusClosed && locked.AutoClosedAt != nil {
+			if err := locked.ApplyStatus(models.StatusReopened, message.CreatedAt); err != nil {
+				return err
+			}
+			reopened = true
+		}
+		if models.IsFinalStatus(locked.Status) {
+			return errTicketClosed
 		}
-	}()
-
-	go func() {
 
-		data := url.Values{}
-		data.Set("super_connect_token", "super_secret_key_2024")
-		data.Set("sender_id", "5259653323")
-		data.Set("message", "В вашем тиките "+strconv.Itoa(ticketID)+" обновление"+request.Message)
-		data.Set("accepter_id", strconv.Itoa(ticekt_user_id))
+		if err := tx.Messages().Create(ctx, &message); err != nil {
+			return err
+		}
+		changes := []models.TicketHistoryEntry{
+			models.NewHistoryEntry(ticketID, models.HistoryFieldMessage, "", strconv.Itoa(message.ID), actorOf(identity), message.CreatedAt),
+		}
+		if reopened {
+			changes = append(changes, models.NewHistoryEntry(ticketID, models.HistoryFieldStatus, models.StatusClosed, locked.Status, models.SystemActor(), message.CreatedAt))
+		}
 
-		resp, err := http.PostForm("http://localhost:8443/superconnect", data)
-		if err != nil {
-			logger.LogError("Ошибка при отправке уведомления: %v", err)
-			return
+		// Первое сообщение поддержки выполняет срок первого ответа SLA
+		if identity.IsStaff() && locked.FirstResponseAt == nil {
+			locked.FirstResponseAt = &message.CreatedAt
+		}
+		// Ответ поддержки запускает ожидание клиента, сообщение клиента его завершает
+		locked.TrackReply(identity.IsStaff(), message.CreatedAt)
+		if err := tx.Tickets().Update(ctx, locked); err != nil {
+			return err
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			logger.LogError("Ошибка при отправке уведомления, код ответа: %d", resp.StatusCode)
+		if err := tx.History().Add(ctx, changes...); err != nil {
+			return err
 		}
-	}()
+		return h.outbox.Enqueue(ctx, tx, notify.Notification{
+			Event:       notify.EventMessageAdded,
+			TicketID:    ticketID,
+			RecipientID: ticket.UserID,
+			Message:     fmt.Sprintf("В вашем тикете %d новое сообщение: %s", ticketID, request.Message),
+		})
+	})
+	if errors.Is(err, errTicketClosed) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя добавить сообщение в закрытый тикет"})
+		return
+	}
+	if err != nil {
+		logger.LogError("Ошибка при добавлении сообщения: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении сообщения"})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message":    "Сообщение добавлено успешно",
-		"message_id": messageID,
+		"message_id": message.ID,
 	})
 }
 
 // GetTicketMessages возвращает сообщения тикета
-func GetTicketMessages(c *gin.Context) {
+func (h *Handler) GetTicketMessages(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
 	ticketID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
 		return
 	}
 
-	// Проверяем существование тикета
-	var exists bool
-	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM tickets WHERE id = $1)", ticketID).Scan(&exists)
-	if err != nil {
-		logger.LogError("Ошибка при проверке тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сообщений"})
+	// Проверяем существование тикета и доступ к нему
+	if _, ok := h.loadTicket(c, identity, ticketID, "Ошибка при получении сообщений"); !ok {
 		return
 	}
 
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
+	// Пагинация; без параметров возвращаются все сообщения, как раньше
+	page, ok := parseListPage(c, 0, "messages", repository.KeyTime)
+	if !ok {
 		return
 	}
 
+	// Внутренние заметки видит только поддержка
+	filter := repository.MessageFilter{WithoutNotes: !identity.IsStaff(), Page: page.Page}
+
 	// Получаем сообщения
-	rows, err := db.DB.Query(
-		"SELECT id, ticket_id, sender_type, sender_id, message, created_at FROM ticket_messages WHERE ticket_id = $1 ORDER BY created_at",
-		ticketID,
-	)
+	messages, err := h.store.Messages().ListByTicket(ctx, ticketID, filter)
 	if err != nil {
 		logger.LogError("Ошибка при получении сообщений: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сообщений"})
 		return
 	}
-	defer rows.Close()
-
-	var messages []models.TicketMessage
-	for rows.Next() {
-		var message models.TicketMessage
-		if err := rows.Scan(
-			&message.ID,
-			&message.TicketID,
-			&message.SenderType,
-			&message.SenderID,
-			&message.Message,
-			&message.CreatedAt,
-		); err != nil {
-			logger.LogError("Ошибка при сканировании сообщения: %v", err)
-			continue
+
+	count := len(messages)
+	if !page.cursorMode() && page.Limit > 0 {
+		if count, err = h.store.Messages().CountByTicket(ctx, ticketID, filter); err != nil {
+			logger.LogError("Ошибка при подсчете сообщений: %v", err)
 		}
-		messages = append(messages, message)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"messages": messages,
+	messages, response := finishPage(page, messages, count, func(message models.TicketMessage) repository.Cursor {
+		return repository.Cursor{Key: repository.TimeKey(&message.CreatedAt), ID: int64(message.ID)}
 	})
+	response["messages"] = messages
+	c.JSON(http.StatusOK, response)
 }
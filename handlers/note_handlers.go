@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mentionPattern находит упоминания сотрудников: @ID или @логин в начале текста или после пробела
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([\p{L}\p{N}._-]+)`)
+
+// AddTicketNote добавляет к тикету внутреннюю заметку. Заметка видна только поддержке,
+// не меняет тикет и сроки SLA, а упомянутые в ней сотрудники получают уведомление.
+func (h *Handler) AddTicketNote(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	ticketID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+
+	var request models.NewNoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Проверяем существование тикета и доступ к нему
+	if _, ok := h.loadTicket(c, identity, ticketID, "Ошибка при добавлении заметки"); !ok {
+		return
+	}
+
+	mentions, err := h.resolveMentions(ctx, request.Message)
+	if err != nil {
+		logger.LogError("Ошибка при разборе упоминаний: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении заметки"})
+		return
+	}
+
+	note := models.TicketMessage{
+		TicketID:   ticketID,
+		SenderType: identity.SenderType(),
+		SenderID:   identity.UserID,
+		Kind:       models.MessageKindNote,
+		Message:    request.Message,
+		CreatedAt:  time.Now(),
+		Mentions:   mentions,
+	}
+
+	// Заметка, история и уведомления упомянутых сохраняются в одной транзакции
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		if err := tx.Messages().Create(ctx, &note); err != nil {
+			return err
+		}
+		entry := models.NewHistoryEntry(ticketID, models.HistoryFieldNote, "", strconv.Itoa(note.ID), actorOf(identity), note.CreatedAt)
+		if err := tx.History().Add(ctx, entry); err != nil {
+			return err
+		}
+
+		for _, agentID := range mentions {
+			if agentID == identity.UserID {
+				continue
+			}
+			err := h.outbox.Enqueue(ctx, tx, notify.Notification{
+				Event:       notify.EventNoteMentioned,
+				TicketID:    ticketID,
+				RecipientID: agentID,
+				Message:     fmt.Sprintf("Вас упомянули в заметке к тикету %d: %s", ticketID, request.Message),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.LogError("Ошибка при добавлении заметки: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении заметки"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Заметка добавлена",
+		"message_id": note.ID,
+		"mentions":   mentions,
+	})
+}
+
+// resolveMentions возвращает ID активных сотрудников, упомянутых в тексте, в порядке упоминания.
+// Логин сотрудника — часть email до @; упоминания неизвестных сотрудников пропускаются.
+func (h *Handler) resolveMentions(ctx context.Context, text string) ([]int64, error) {
+	mentions := []int64{}
+
+	matches := mentionPattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return mentions, nil
+	}
+
+	agents, err := h.store.Agents().List(ctx, repository.Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool)
+	for _, match := range matches {
+		// Знаки препинания после упоминания не входят в логин: "@ivan."
+		handle := strings.TrimRight(match[1], ".-_")
+		for _, agent := range agents {
+			if !agent.IsActive || seen[agent.ID] || !mentionsAgent(handle, agent) {
+				continue
+			}
+			seen[agent.ID] = true
+			mentions = append(mentions, agent.ID)
+		}
+	}
+	return mentions, nil
+}
+
+// mentionsAgent сообщает, что упоминание указывает на сотрудника по ID или логину
+func mentionsAgent(handle string, agent models.Agent) bool {
+	if handle == strconv.FormatInt(agent.ID, 10) {
+		return true
+	}
+	login, _, found := strings.Cut(agent.Email, "@")
+	return found && login != "" && strings.EqualFold(login, handle)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotifications возвращает записи очереди уведомлений, по умолчанию недоставленные (dead)
+func (h *Handler) GetNotifications(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	status := c.DefaultQuery("status", models.OutboxDead)
+	if status == "all" {
+		status = ""
+	} else if status != models.OutboxPending && status != models.OutboxSent && status != models.OutboxDead {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный статус уведомления"})
+		return
+	}
+
+	// Пагинация; общее количество записей очереди не считается
+	page, ok := parseListPage(c, 20, "notifications", repository.KeyNone)
+	if !ok {
+		return
+	}
+	page.withoutCount()
+
+	entries, err := h.store.Outbox().List(ctx, status, page.Page)
+	if err != nil {
+		logger.LogError("Ошибка при получении очереди уведомлений: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении уведомлений"})
+		return
+	}
+
+	entries, response := finishPage(page, entries, 0, func(entry models.OutboxEntry) repository.Cursor {
+		return repository.Cursor{ID: entry.ID}
+	})
+	response["notifications"] = entries
+	c.JSON(http.StatusOK, response)
+}
+
+// ReplayNotification возвращает недоставленное уведомление в очередь
+func (h *Handler) ReplayNotification(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID уведомления"})
+		return
+	}
+
+	entry, err := h.store.Outbox().GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Уведомление не найдено"})
+		} else {
+			logger.LogError("Ошибка при получении уведомления: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при повторной отправке уведомления"})
+		}
+		return
+	}
+
+	if entry.Status != models.OutboxDead {
+		c.JSON(http.StatusConflict, gin.H{"error": "Повторно отправить можно только недоставленное уведомление"})
+		return
+	}
+
+	if err := h.store.Outbox().Replay(ctx, id, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Повторно отправить можно только недоставленное уведомление"})
+		} else {
+			logger.LogError("Ошибка при повторной отправке уведомления: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при повторной отправке уведомления"})
+		}
+		return
+	}
+
+	logger.LogInfo("Уведомление %d возвращено в очередь", id)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Уведомление возвращено в очередь",
+		"notification_id": id,
+	})
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"support_front_api/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPageLimit — наибольший размер страницы, который можно запросить параметром limit
+const maxPageLimit = 100
+
+// defaultCursorLimit — размер страницы в режиме курсора для списков, которые без пагинации
+// отдаются целиком
+const defaultCursorLimit = 50
+
+// cursorToken — содержимое непрозрачного курсора. Order привязывает курсор к списку
+// и сортировке, в которых он выдан.
+type cursorToken struct {
+	Order string `json:"o"`
+	Key   string `json:"k,omitempty"`
+	ID    int64  `json:"i"`
+}
+
+// encodeCursor записывает курсор строкой base64url
+func encodeCursor(order string, cursor repository.Cursor) string {
+	data, _ := json.Marshal(cursorToken{Order: order, Key: cursor.Key, ID: cursor.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor разбирает курсор и проверяет, что он выдан для того же списка и сортировки
+func decodeCursor(value, order, kind string) (*repository.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("Неверный курсор")
+	}
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil || !repository.ValidKey(kind, token.Key) {
+		return nil, errors.New("Неверный курсор")
+	}
+	if token.Order != order {
+		return nil, errors.New("Курсор выдан для другого списка или сортировки")
+	}
+	return &repository.Cursor{Key: token.Key, ID: token.ID}, nil
+}
+
+// listPage — параметры страницы списка: номер страницы со смещением или курсор after/before
+type listPage struct {
+	repository.Page
+	page  int
+	limit int
+	// order — список и сортировка, к которым привязаны курсоры
+	order string
+	// probe — выбирается на одну запись больше limit, чтобы узнать has_more без подсчета
+	probe bool
+}
+
+// cursorMode сообщает, что страница запрошена по курсору
+func (p listPage) cursorMode() bool {
+	return p.After != nil || p.Before != nil
+}
+
+// withoutCount включает выборку лишней записи в режиме смещения для списков,
+// количество записей в которых не считается
+func (p *listPage) withoutCount() {
+	if !p.probe && p.limit > 0 {
+		p.Limit = p.limit + 1
+		p.probe = true
+	}
+}
+
+// parsePageLimit разбирает номер страницы page (с 1) и размер страницы limit (от 1 до maxPageLimit).
+// Без limit используется defaultLimit; нулевой defaultLimit — список целиком. При ошибке отвечает 400.
+func parsePageLimit(c *gin.Context, defaultLimit int) (int, int, bool) {
+	page := 1
+	if value := c.Query("page"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Номер страницы page должен быть целым числом от 1"})
+			return 0, 0, false
+		}
+		page = int(n)
+	}
+
+	limit := defaultLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Размер страницы limit должен быть целым числом от 1 до %d", maxPageLimit)})
+			return 0, 0, false
+		}
+		limit = n
+	}
+	return page, limit, true
+}
+
+// parseListPage разбирает page/limit или курсор after/before. В режиме курсора выбирается
+// на одну запись больше, чтобы узнать has_more без подсчета. При ошибке отвечает 400.
+func parseListPage(c *gin.Context, defaultLimit int, order, kind string) (listPage, bool) {
+	p := listPage{order: order}
+	var ok bool
+	if p.page, p.limit, ok = parsePageLimit(c, defaultLimit); !ok {
+		return p, false
+	}
+
+	after, before := c.Query("after"), c.Query("before")
+	if after == "" && before == "" {
+		p.Limit = p.limit
+		p.Offset = (p.page - 1) * p.limit
+		return p, true
+	}
+	if after != "" && before != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите только один курсор: after или before"})
+		return p, false
+	}
+
+	var err error
+	if after != "" {
+		p.After, err = decodeCursor(after, order, kind)
+	} else {
+		p.Before, err = decodeCursor(before, order, kind)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return p, false
+	}
+
+	if p.limit <= 0 {
+		p.limit = defaultCursorLimit
+	}
+	p.Limit = p.limit + 1
+	p.probe = true
+	return p, true
+}
+
+// finishPage обрезает лишнюю запись и собирает поля пагинации ответа: курсоры первой
+// и последней записи и has_more. В режиме смещения добавляются page и total, если он посчитан.
+func finishPage[T any](p listPage, items []T, total int, key func(T) repository.Cursor) ([]T, gin.H) {
+	var hasMore bool
+	if p.probe {
+		hasMore = len(items) > p.limit
+		if hasMore && p.Before != nil {
+			items = items[len(items)-p.limit:]
+		} else if hasMore {
+			items = items[:p.limit]
+		}
+	} else {
+		hasMore = p.Offset+len(items) < total
+	}
+
+	response := gin.H{
+		"limit":       p.limit,
+		"has_more":    hasMore,
+		"next_cursor": nil,
+		"prev_cursor": nil,
+	}
+	if len(items) > 0 {
+		response["prev_cursor"] = encodeCursor(p.order, key(items[0]))
+		response["next_cursor"] = encodeCursor(p.order, key(items[len(items)-1]))
+	}
+	if !p.cursorMode() {
+		response["page"] = p.page
+		if !p.probe {
+			response["total"] = total
+		}
+	}
+	return items, response
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"support_front_api/repository"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		order  string
+		kind   string
+		cursor repository.Cursor
+	}{
+		{"-id", repository.KeyNone, repository.Cursor{ID: 42}},
+		{"priority", repository.KeyInt, repository.Cursor{Key: "3", ID: 7}},
+		{"category", repository.KeyText, repository.Cursor{Key: "доступ", ID: 1}},
+		{"trash:-closed_at", repository.KeyTime, repository.Cursor{Key: repository.KeyInfinity, ID: 5}},
+	}
+
+	for _, tt := range tests {
+		decoded, err := decodeCursor(encodeCursor(tt.order, tt.cursor), tt.order, tt.kind)
+		if err != nil {
+			t.Errorf("%s: %v", tt.order, err)
+			continue
+		}
+		if *decoded != tt.cursor {
+			t.Errorf("%s: получено %+v, ожидалось %+v", tt.order, *decoded, tt.cursor)
+		}
+	}
+}
+
+func TestDecodeCursorErrors(t *testing.T) {
+	valid := encodeCursor("priority", repository.Cursor{Key: "3", ID: 7})
+
+	tests := []struct {
+		name        string
+		value       string
+		order, kind string
+	}{
+		{"не base64", "%%%", "priority", repository.KeyInt},
+		{"не JSON", "bm90LWpzb24", "priority", repository.KeyInt},
+		{"другая сортировка", valid, "-priority", repository.KeyInt},
+		{"другой список", valid, "agents", repository.KeyInt},
+		{"ключ не того типа", valid, "priority", repository.KeyTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.value, tt.order, tt.kind); err == nil {
+				t.Error("ожидалась ошибка")
+			}
+		})
+	}
+}
+
+func TestFinishPage(t *testing.T) {
+	key := func(id int) repository.Cursor { return repository.Cursor{ID: int64(id)} }
+
+	t.Run("после курсора обрезается хвост", func(t *testing.T) {
+		p := listPage{Page: repository.Page{After: &repository.Cursor{ID: 1}, Limit: 3}, limit: 2, probe: true}
+		items, response := finishPage(p, []int{2, 3, 4}, 0, key)
+		if len(items) != 2 || items[0] != 2 || response["has_more"] != true {
+			t.Errorf("items = %v, has_more = %v", items, response["has_more"])
+		}
+		if _, ok := response["page"]; ok {
+			t.Error("в режиме курсора не возвращается page")
+		}
+	})
+
+	t.Run("до курсора обрезается начало", func(t *testing.T) {
+		p := listPage{Page: repository.Page{Before: &repository.Cursor{ID: 9}, Limit: 3}, limit: 2, probe: true}
+		items, response := finishPage(p, []int{5, 6, 7}, 0, key)
+		if len(items) != 2 || items[0] != 6 || response["has_more"] != true {
+			t.Errorf("items = %v, has_more = %v", items, response["has_more"])
+		}
+	})
+
+	t.Run("смещение с подсчетом", func(t *testing.T) {
+		p := listPage{Page: repository.Page{Limit: 2, Offset: 2}, page: 2, limit: 2}
+		_, response := finishPage(p, []int{3, 4}, 5, key)
+		if response["has_more"] != true || response["total"] != 5 || response["page"] != 2 {
+			t.Errorf("response = %v", response)
+		}
+	})
+
+	t.Run("пустая страница", func(t *testing.T) {
+		p := listPage{Page: repository.Page{Limit: 2}, page: 1, limit: 2}
+		_, response := finishPage(p, []int(nil), 0, key)
+		if response["next_cursor"] != nil || response["has_more"] != false {
+			t.Errorf("response = %v", response)
+		}
+	})
+}
+
+func TestParseListPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		defaultLim int
+		wantOK     bool
+		wantLimit  int
+		wantOffset int
+	}{
+		{"по умолчанию", "", 20, true, 20, 0},
+		{"страница и размер", "?page=3&limit=10", 20, true, 10, 20},
+		{"список целиком по умолчанию", "", 0, true, 0, 0},
+		{"наибольший размер", "?limit=100", 20, true, 100, 0},
+		{"нулевой размер", "?limit=0", 20, false, 0, 0},
+		{"отрицательный размер", "?limit=-1", 20, false, 0, 0},
+		{"слишком большой размер", "?limit=101", 20, false, 0, 0},
+		{"размер не числом", "?limit=all", 20, false, 0, 0},
+		{"нулевая страница", "?page=0", 20, false, 0, 0},
+		{"отрицательная страница", "?page=-2", 20, false, 0, 0},
+		{"страница не числом", "?page=x", 20, false, 0, 0},
+		{"огромная страница", "?page=99999999999999", 20, false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/users"+tt.query, nil)
+
+			p, ok := parseListPage(c, tt.defaultLim, "users", repository.KeyNone)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, ожидалось %v", ok, tt.wantOK)
+			}
+			if !ok {
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("код %d, ожидался 400", w.Code)
+				}
+				return
+			}
+			if p.Limit != tt.wantLimit || p.Offset != tt.wantOffset {
+				t.Errorf("Limit = %d, Offset = %d, ожидалось %d и %d", p.Limit, p.Offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
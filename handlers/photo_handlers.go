@@ -1,14 +1,15 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
-	"support_front_api/db"
 	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,39 +17,29 @@ import (
 )
 
 // UploadTicketPhoto загружает фотографию к тикету
-func UploadTicketPhoto(c *gin.Context) {
-	// Получаем ID тикета
-	ticketID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
-		return
-	}
+func (h *Handler) UploadTicketPhoto(c *gin.Context) {
+	ctx := c.Request.Context()
 
-	// Проверяем существование тикета
-	var exists bool
-	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM tickets WHERE id = $1)", ticketID).Scan(&exists)
-	if err != nil {
-		logger.LogError("Ошибка при проверке тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при загрузке фотографии"})
+	identity, ok := currentIdentity(c)
+	if !ok {
 		return
 	}
 
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
+	// Получаем ID тикета
+	ticketID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
 		return
 	}
 
-	// Получаем данные отправителя
-	senderType := c.PostForm("sender_type")
-	senderID, err := strconv.ParseInt(c.PostForm("sender_id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID отправителя"})
+	// Проверяем существование тикета и доступ к нему
+	if _, ok := h.loadTicket(c, identity, ticketID, "Ошибка при загрузке фотографии"); !ok {
 		return
 	}
 
 	// Получаем сообщение (опционально)
 	messageIDStr := c.PostForm("message_id")
-	var messageID sql.NullInt32
+	var messageID *int
 
 	if messageIDStr != "" {
 		msgID, err := strconv.Atoi(messageIDStr)
@@ -56,8 +47,7 @@ func UploadTicketPhoto(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID сообщения"})
 			return
 		}
-		messageID.Int32 = int32(msgID)
-		messageID.Valid = true
+		messageID = &msgID
 	}
 
 	// Получаем файл
@@ -98,25 +88,23 @@ func UploadTicketPhoto(c *gin.Context) {
 	}
 
 	// Сохраняем информацию о фотографии в базу данных
-	var photoID int
-	query := `
-		INSERT INTO ticket_photos 
-		(ticket_id, sender_type, sender_id, file_path, file_id, message_id, created_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7) 
-		RETURNING id
-	`
-
-	err = db.DB.QueryRow(
-		query,
-		ticketID,
-		senderType,
-		senderID,
-		filePath,
-		fileID,
-		messageID,
-		time.Now(),
-	).Scan(&photoID)
-
+	photo := models.TicketPhoto{
+		TicketID:   ticketID,
+		SenderType: identity.SenderType(),
+		SenderID:   identity.UserID,
+		FilePath:   filePath,
+		FileID:     fileID,
+		MessageID:  messageID,
+		CreatedAt:  time.Now(),
+	}
+
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		if err := tx.Photos().Create(ctx, &photo); err != nil {
+			return err
+		}
+		entry := models.NewHistoryEntry(ticketID, models.HistoryFieldPhoto, "", strconv.Itoa(photo.ID), actorOf(identity), photo.CreatedAt)
+		return tx.History().Add(ctx, entry)
+	})
 	if err != nil {
 		logger.LogError("Ошибка при сохранении информации о фотографии: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при загрузке фотографии"})
@@ -125,14 +113,21 @@ func UploadTicketPhoto(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message":   "Фотография успешно загружена",
-		"photo_id":  photoID,
+		"photo_id":  photo.ID,
 		"file_id":   fileID,
 		"file_path": filePath,
 	})
 }
 
 // GetTicketPhoto получает фотографию тикета
-func GetTicketPhoto(c *gin.Context) {
+func (h *Handler) GetTicketPhoto(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
 	// Получаем ID фотографии
 	photoID, err := strconv.Atoi(c.Param("photo_id"))
 	if err != nil {
@@ -141,14 +136,9 @@ func GetTicketPhoto(c *gin.Context) {
 	}
 
 	// Получаем информацию о фотографии
-	var filePath string
-	err = db.DB.QueryRow(
-		"SELECT file_path FROM ticket_photos WHERE id = $1",
-		photoID,
-	).Scan(&filePath)
-
+	photo, err := h.store.Photos().GetByID(ctx, photoID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, repository.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Фотография не найдена"})
 		} else {
 			logger.LogError("Ошибка при получении информации о фотографии: %v", err)
@@ -157,6 +147,13 @@ func GetTicketPhoto(c *gin.Context) {
 		return
 	}
 
+	// Проверяем доступ к тикету фотографии
+	if _, ok := h.loadTicket(c, identity, photo.TicketID, "Ошибка при получении фотографии"); !ok {
+		return
+	}
+
+	filePath := photo.FilePath
+
 	// Проверяем существование файла
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		logger.LogError("Файл не найден: %v", filePath)
@@ -169,7 +166,14 @@ func GetTicketPhoto(c *gin.Context) {
 }
 
 // DeleteTicketPhoto удаляет фотографию тикета
-func DeleteTicketPhoto(c *gin.Context) {
+func (h *Handler) DeleteTicketPhoto(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
 	// Получаем ID фотографии
 	photoID, err := strconv.Atoi(c.Param("photo_id"))
 	if err != nil {
@@ -178,14 +182,9 @@ func DeleteTicketPhoto(c *gin.Context) {
 	}
 
 	// Получаем информацию о фотографии
-	var filePath string
-	err = db.DB.QueryRow(
-		"SELECT file_path FROM ticket_photos WHERE id = $1",
-		photoID,
-	).Scan(&filePath)
-
+	photo, err := h.store.Photos().GetByID(ctx, photoID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, repository.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Фотография не найдена"})
 		} else {
 			logger.LogError("Ошибка при получении информации о фотографии: %v", err)
@@ -194,8 +193,20 @@ func DeleteTicketPhoto(c *gin.Context) {
 		return
 	}
 
-	// Удаляем запись из базы данных
-	_, err = db.DB.Exec("DELETE FROM ticket_photos WHERE id = $1", photoID)
+	// Клиент может удалить только загруженную им фотографию
+	if !identity.IsStaff() && (photo.SenderType != identity.SenderType() || photo.SenderID != identity.UserID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ к фотографии запрещен"})
+		return
+	}
+
+	// Удаляем запись из базы данных и отмечаем удаление в истории тикета
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		if err := tx.Photos().Delete(ctx, photoID); err != nil {
+			return err
+		}
+		entry := models.NewHistoryEntry(photo.TicketID, models.HistoryFieldPhoto, strconv.Itoa(photoID), "", actorOf(identity), time.Now())
+		return tx.History().Add(ctx, entry)
+	})
 	if err != nil {
 		logger.LogError("Ошибка при удалении записи о фотографии: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении фотографии"})
@@ -203,8 +214,8 @@ func DeleteTicketPhoto(c *gin.Context) {
 	}
 
 	// Удаляем файл
-	if _, err := os.Stat(filePath); err == nil {
-		if err := os.Remove(filePath); err != nil {
+	if _, err := os.Stat(photo.FilePath); err == nil {
+		if err := os.Remove(photo.FilePath); err != nil {
 			logger.LogWarning("Не удалось удалить файл фотографии: %v", err)
 		}
 	}
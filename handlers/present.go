@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"support_front_api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// presentTickets заполняет вычисляемые поля тикетов: подписи на языке запроса и состояние SLA
+func (h *Handler) presentTickets(c *gin.Context, tickets ...*models.Ticket) {
+	lang := requestLang(c)
+	now := time.Now()
+	for _, ticket := range tickets {
+		ticket.Localize(lang)
+		ticket.SLAStatus = h.sla.Status(ticket, now)
+	}
+}
+
+// presentTicketList — presentTickets для среза тикетов
+func (h *Handler) presentTicketList(c *gin.Context, tickets []models.Ticket) {
+	for i := range tickets {
+		h.presentTickets(c, &tickets[i])
+	}
+}
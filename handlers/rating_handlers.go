@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateTicket сохраняет оценку закрытого тикета его автором. Тикет оценивается один раз.
+func (h *Handler) RateTicket(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	ticketID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+
+	var request models.NewRatingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Проверяем существование тикета и доступ к нему
+	ticket, ok := h.loadTicket(c, identity, ticketID, "Ошибка при сохранении оценки")
+	if !ok {
+		return
+	}
+	if ticket.UserID != identity.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Оценить тикет может только его автор"})
+		return
+	}
+
+	rating := models.TicketRating{
+		TicketID:  ticketID,
+		UserID:    identity.UserID,
+		Score:     request.Score,
+		Comment:   request.Comment,
+		CreatedAt: time.Now(),
+	}
+
+	// Тикет блокируется, чтобы его не переоткрыли, пока сохраняется оценка
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		locked, err := tx.Tickets().GetForUpdate(ctx, ticketID)
+		if err != nil {
+			return err
+		}
+		if locked.MergedIntoID != nil {
+			return &requestError{http.StatusBadRequest, fmt.Sprintf("Тикет объединен с тикетом %d, оцените его", *locked.MergedIntoID)}
+		}
+		if locked.Status != models.StatusClosed {
+			return &requestError{http.StatusBadRequest, "Оценить можно только закрытый тикет"}
+		}
+
+		rating.AgentID = locked.AssigneeID
+		rating.Category = locked.Category
+		created, err := tx.Ratings().Create(ctx, &rating)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return &requestError{http.StatusConflict, "Тикет уже оценен"}
+		}
+
+		entry := models.NewHistoryEntry(ticketID, models.HistoryFieldRating, "", strconv.Itoa(rating.Score), actorOf(identity), rating.CreatedAt)
+		return tx.History().Add(ctx, entry)
+	})
+	if err != nil {
+		var reqErr *requestError
+		if errors.As(err, &reqErr) {
+			c.JSON(reqErr.status, gin.H{"error": reqErr.message})
+			return
+		}
+		logger.LogError("Ошибка при сохранении оценки: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сохранении оценки"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Спасибо за оценку",
+		"rating":  rating,
+	})
+}
+
+// ticketRating возвращает оценку тикета или nil, если тикет не оценен
+func (h *Handler) ticketRating(c *gin.Context, ticketID int) (*models.TicketRating, error) {
+	rating, err := h.store.Ratings().GetByTicket(c.Request.Context(), ticketID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return rating, err
+}
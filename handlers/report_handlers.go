@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reportDateLayout — формат дат периода отчета
+const reportDateLayout = "2006-01-02"
+
+// defaultReportDays — длина периода отчета по умолчанию
+const defaultReportDays = 30
+
+// GetFirstResponseReport возвращает время первого ответа по тикетам, созданным за период.
+// Время считается в рабочих часах календаря политики SLA тикета.
+func (h *Handler) GetFirstResponseReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	now := time.Now()
+
+	from, to, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	end := to.AddDate(0, 0, 1)
+	tickets, err := h.store.Tickets().List(ctx, repository.TicketFilter{CreatedFrom: &from, CreatedTo: &end})
+	if err != nil {
+		logger.LogError("Ошибка при построении отчета по первому ответу: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при построении отчета"})
+		return
+	}
+
+	var durations []time.Duration
+	breached := 0
+	for i := range tickets {
+		ticket := &tickets[i]
+		if ticket.FirstResponseAt != nil {
+			durations = append(durations, h.sla.BusinessTime(ticket, ticket.CreatedAt, *ticket.FirstResponseAt))
+		}
+		if ticket.DeadlineStatus(models.DeadlineFirstResponse, now) == models.SLABreached {
+			breached++
+		}
+	}
+
+	report := gin.H{
+		"from":      from.Format(reportDateLayout),
+		"to":        to.Format(reportDateLayout),
+		"tickets":   len(tickets),
+		"responded": len(durations),
+		"breached":  breached,
+	}
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+		var total time.Duration
+		for _, d := range durations {
+			total += d
+		}
+		median := durations[len(durations)/2]
+		if len(durations)%2 == 0 {
+			median = (durations[len(durations)/2-1] + median) / 2
+		}
+
+		report["average_minutes"] = roundMinutes(total / time.Duration(len(durations)))
+		report["median_minutes"] = roundMinutes(median)
+		report["max_minutes"] = roundMinutes(durations[len(durations)-1])
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// csatStats накапливает оценки для отчета CSAT
+type csatStats struct {
+	count     int
+	total     int
+	satisfied int
+}
+
+func (s *csatStats) add(rating models.TicketRating) {
+	s.count++
+	s.total += rating.Score
+	if rating.Satisfied() {
+		s.satisfied++
+	}
+}
+
+// report возвращает число оценок, среднюю оценку и CSAT — долю довольных клиентов в процентах
+func (s *csatStats) report() gin.H {
+	report := gin.H{"ratings": s.count}
+	if s.count > 0 {
+		report["average_score"] = math.Round(float64(s.total)/float64(s.count)*100) / 100
+		report["csat_percent"] = math.Round(float64(s.satisfied)/float64(s.count)*1000) / 10
+	}
+	return report
+}
+
+// GetCSATReport возвращает удовлетворенность клиентов по оценкам, поставленным за период:
+// в целом, по ответственным сотрудникам и по категориям. Сотрудник и категория берутся
+// на момент оценки.
+func (h *Handler) GetCSATReport(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	from, to, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	end := to.AddDate(0, 0, 1)
+	ratings, err := h.store.Ratings().List(ctx, repository.RatingFilter{From: &from, To: &end})
+	if err != nil {
+		logger.LogError("Ошибка при построении отчета CSAT: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при построении отчета"})
+		return
+	}
+
+	var overall csatStats
+	distribution := make(map[string]int)
+	for score := models.MinRatingScore; score <= models.MaxRatingScore; score++ {
+		distribution[strconv.Itoa(score)] = 0
+	}
+	byAgent := make(map[int64]*csatStats)
+	byCategory := make(map[string]*csatStats)
+	for _, rating := range ratings {
+		overall.add(rating)
+		distribution[strconv.Itoa(rating.Score)]++
+
+		// Тикеты без ответственного собираются под agent_id 0
+		var agentID int64
+		if rating.AgentID != nil {
+			agentID = *rating.AgentID
+		}
+		if byAgent[agentID] == nil {
+			byAgent[agentID] = &csatStats{}
+		}
+		byAgent[agentID].add(rating)
+
+		if byCategory[rating.Category] == nil {
+			byCategory[rating.Category] = &csatStats{}
+		}
+		byCategory[rating.Category].add(rating)
+	}
+
+	lang := requestLang(c)
+
+	agents := make([]gin.H, 0, len(byAgent))
+	for agentID, stats := range byAgent {
+		row := stats.report()
+		row["agent_id"] = agentID
+		agents = append(agents, row)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i]["agent_id"].(int64) < agents[j]["agent_id"].(int64) })
+
+	categories := make([]gin.H, 0, len(byCategory))
+	for category, stats := range byCategory {
+		row := stats.report()
+		row["category"] = category
+		row["category_label"] = models.CategoryLabel(category, lang)
+		categories = append(categories, row)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i]["category"].(string) < categories[j]["category"].(string) })
+
+	report := overall.report()
+	report["from"] = from.Format(reportDateLayout)
+	report["to"] = to.Format(reportDateLayout)
+	report["distribution"] = distribution
+	report["by_agent"] = agents
+	report["by_category"] = categories
+	c.JSON(http.StatusOK, report)
+}
+
+// parseReportPeriod разбирает период отчета: from и to включительно, по умолчанию
+// последние defaultReportDays дней. При ошибке отвечает 400.
+func parseReportPeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -defaultReportDays+1)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(reportDateLayout, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверная дата from, ожидается ГГГГ-ММ-ДД"})
+			return from, to, false
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(reportDateLayout, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверная дата to, ожидается ГГГГ-ММ-ДД"})
+			return from, to, false
+		}
+		to = parsed
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Дата to раньше даты from"})
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// roundMinutes переводит длительность в минуты с точностью до десятых
+func roundMinutes(d time.Duration) float64 {
+	return math.Round(d.Minutes()*10) / 10
+}
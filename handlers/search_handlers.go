@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"support_front_api/logger"
+	"support_front_api/repository"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSearchQueryLength ограничивает длину поискового запроса в символах
+const maxSearchQueryLength = 200
+
+// SearchTickets ищет тикеты по заголовку, описанию и сообщениям
+func (h *Handler) SearchTickets(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не указан поисковый запрос q"})
+		return
+	}
+	if utf8.RuneCountInString(text) > maxSearchQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Слишком длинный поисковый запрос"})
+		return
+	}
+
+	// Пагинация только по номеру страницы: результаты упорядочены по релевантности, курсоров нет
+	if c.Query("after") != "" || c.Query("before") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Поиск не поддерживает курсоры, используйте page и limit"})
+		return
+	}
+	page, limit, ok := parsePageLimit(c, 10)
+	if !ok {
+		return
+	}
+	offset := (page - 1) * limit
+
+	query := repository.SearchQuery{
+		Text:   text,
+		Limit:  limit,
+		Offset: offset,
+	}
+	// Клиент ищет только по своим тикетам и не видит внутренних заметок
+	if !identity.IsStaff() {
+		query.UserID = &identity.UserID
+		query.WithoutNotes = true
+	}
+
+	results, err := h.store.Search().Tickets(ctx, query)
+	if err != nil {
+		logger.LogError("Ошибка при поиске тикетов: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при поиске тикетов"})
+		return
+	}
+
+	count, err := h.store.Search().Count(ctx, query)
+	if err != nil {
+		logger.LogError("Ошибка при подсчете найденных тикетов: %v", err)
+	}
+
+	for i := range results {
+		h.presentTickets(c, &results[i].Ticket)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"total":   count,
+		"page":    page,
+		"limit":   limit,
+	})
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"support_front_api/auth"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFilterValues ограничивает число значений в фильтре со списком
+const maxFilterValues = 20
+
+// filterDateLayout — формат даты в фильтрах; также принимается время в RFC 3339
+const filterDateLayout = "2006-01-02"
+
+// filterError — ошибка в параметрах фильтрации; details дополняет ответ допустимыми значениями
+type filterError struct {
+	message string
+	details gin.H
+}
+
+// listValues собирает значения параметра из повторов и списков через запятую: a=1&a=2,3
+func listValues(c *gin.Context, name string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(name) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// parseBound разбирает границу периода: дату ГГГГ-ММ-ДД (UTC) или время RFC 3339.
+// Верхняя граница-дата включает весь день.
+func parseBound(value string, upper bool) (time.Time, error) {
+	if day, err := time.Parse(filterDateLayout, value); err == nil {
+		if upper {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseRange разбирает пару параметров периода name_from и name_to
+func parseRange(c *gin.Context, name string) (*time.Time, *time.Time, *filterError) {
+	var from, to *time.Time
+	for _, bound := range []struct {
+		param string
+		upper bool
+		into  **time.Time
+	}{
+		{name + "_from", false, &from},
+		{name + "_to", true, &to},
+	} {
+		value := c.Query(bound.param)
+		if value == "" {
+			continue
+		}
+		parsed, err := parseBound(value, bound.upper)
+		if err != nil {
+			return nil, nil, &filterError{message: fmt.Sprintf("Неверная дата %s, ожидается ГГГГ-ММ-ДД или RFC 3339", bound.param)}
+		}
+		*bound.into = &parsed
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, &filterError{message: fmt.Sprintf("Пустой период %s_from — %s_to", name, name)}
+	}
+	return from, to, nil
+}
+
+// parseTicketSort разбирает сортировку вида поле или -поле (по убыванию)
+func parseTicketSort(value string) (repository.TicketSort, *filterError) {
+	if value == "" {
+		return repository.DefaultTicketSort, nil
+	}
+
+	sort := repository.TicketSort{Field: value}
+	if strings.HasPrefix(value, "-") {
+		sort = repository.TicketSort{Field: value[1:], Desc: true}
+	}
+	if !repository.ValidTicketSortField(sort.Field) {
+		return sort, &filterError{
+			message: "Неизвестное поле сортировки",
+			details: gin.H{"sort_fields": repository.TicketSortFields},
+		}
+	}
+	return sort, nil
+}
+
+// parseTicketFilter разбирает и проверяет параметры фильтрации и сортировки списка тикетов.
+// Каждое значение проверяется по справочнику или формату, в запрос попадают только параметры.
+func parseTicketFilter(c *gin.Context, identity *auth.Identity) (repository.TicketFilter, *filterError) {
+	var filter repository.TicketFilter
+
+	// Статус принимается кодом или подписью статуса
+	if value := c.Query("status"); value != "" {
+		status, ok := models.ParseStatus(value)
+		if !ok {
+			return filter, &filterError{message: "Неизвестный статус тикета", details: gin.H{"statuses": models.Statuses}}
+		}
+		filter.Status = status
+	}
+
+	if value := c.Query("priority"); value != "" {
+		priority, ok := models.ParsePriority(value)
+		if !ok {
+			return filter, &filterError{message: "Неизвестный приоритет тикета", details: gin.H{"priorities": models.Priorities}}
+		}
+		filter.Priority = priority
+	}
+
+	// Категории: несколько значений, подписи известных категорий приводятся к кодам
+	categories := listValues(c, "category")
+	if len(categories) > maxFilterValues {
+		return filter, &filterError{message: fmt.Sprintf("В фильтре category не больше %d значений", maxFilterValues)}
+	}
+	for _, category := range categories {
+		filter.Categories = append(filter.Categories, models.NormalizeCategory(category))
+	}
+
+	// Состояние SLA: ok, at_risk или breached
+	if value := c.Query("sla_status"); value != "" {
+		if !models.ValidSLAStatus(value) {
+			return filter, &filterError{message: "Неизвестное состояние SLA", details: gin.H{"sla_statuses": models.SLAStatuses}}
+		}
+		filter.SLA = &repository.SLAFilter{Status: value, Now: time.Now()}
+	}
+
+	// Ответственный: me, unassigned или ID сотрудника
+	switch assignee := c.Query("assignee"); assignee {
+	case "":
+	case "me":
+		filter.AssigneeID = &identity.UserID
+	case "unassigned":
+		filter.Unassigned = true
+	default:
+		agentID, err := strconv.ParseInt(assignee, 10, 64)
+		if err != nil {
+			return filter, &filterError{message: "Неверный фильтр по ответственному"}
+		}
+		filter.AssigneeID = &agentID
+	}
+
+	// Автор тикета; клиент видит только свои тикеты, поэтому для него фильтр задается всегда
+	if value := c.Query("user_id"); value != "" {
+		userID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return filter, &filterError{message: "Неверный фильтр по пользователю"}
+		}
+		filter.UserID = &userID
+	}
+	if !identity.IsStaff() {
+		filter.UserID = &identity.UserID
+	}
+
+	var ferr *filterError
+	if filter.CreatedFrom, filter.CreatedTo, ferr = parseRange(c, "created"); ferr != nil {
+		return filter, ferr
+	}
+	if filter.ClosedFrom, filter.ClosedTo, ferr = parseRange(c, "closed"); ferr != nil {
+		return filter, ferr
+	}
+
+	// Дополнительные поля: cf[ключ]=значение; значения приводятся к типам полей в normalizeFieldFilter
+	customFields := c.QueryMap("cf")
+	if len(customFields) > maxFilterValues {
+		return filter, &filterError{message: fmt.Sprintf("В фильтре cf не больше %d полей", maxFilterValues)}
+	}
+	for key, value := range customFields {
+		if !models.ValidFieldKey(key) {
+			return filter, &filterError{message: fmt.Sprintf("Неверный ключ дополнительного поля %q", key)}
+		}
+		if filter.CustomFields == nil {
+			filter.CustomFields = make(map[string]interface{})
+		}
+		filter.CustomFields[key] = value
+	}
+
+	// Есть ли сообщения клиента без ответа поддержки
+	if value := c.Query("unanswered"); value != "" {
+		unanswered, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, &filterError{message: "Фильтр unanswered принимает true или false"}
+		}
+		filter.Unanswered = &unanswered
+	}
+
+	if filter.Sort, ferr = parseTicketSort(c.Query("sort")); ferr != nil {
+		return filter, ferr
+	}
+
+	return filter, nil
+}
+
+// respondFilterError отвечает 400 с описанием ошибки фильтра
+func respondFilterError(c *gin.Context, ferr *filterError) {
+	response := gin.H{"error": ferr.message}
+	for key, value := range ferr.details {
+		response[key] = value
+	}
+	c.JSON(http.StatusBadRequest, response)
+}
@@ -1,222 +1,193 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
 	"strconv"
-	"support_front_api/db"
 	"support_front_api/logger"
 	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetAllTickets возвращает список всех тикетов
-func GetAllTickets(c *gin.Context) {
-	// Пагинация
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset := (page - 1) * limit
-
-	// Фильтрация по статусу
-	status := c.Query("status")
-
-	var rows *sql.Rows
-	var err error
-
-	if status != "" {
-		rows, err = db.DB.Query(
-			"SELECT id, user_id, title, description, status, category, created_at, closed_at FROM tickets WHERE status = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3",
-			status, limit, offset,
-		)
-	} else {
-		rows, err = db.DB.Query(
-			"SELECT id, user_id, title, description, status, category, created_at, closed_at FROM tickets ORDER BY created_at DESC LIMIT $1 OFFSET $2",
-			limit, offset,
-		)
-	}
+func (h *Handler) GetAllTickets(c *gin.Context) {
+	h.listTickets(c, false)
+}
 
-	if err != nil {
-		logger.LogError("Ошибка при получении тикетов: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении тикетов"})
+// listTickets отвечает списком тикетов или корзины (deleted) с фильтрами и пагинацией
+func (h *Handler) listTickets(c *gin.Context, deleted bool) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
 		return
 	}
-	defer rows.Close()
-
-	var tickets []models.Ticket
-	for rows.Next() {
-		var ticket models.Ticket
-		var closedAt sql.NullTime
-
-		if err := rows.Scan(
-			&ticket.ID,
-			&ticket.UserID,
-			&ticket.Title,
-			&ticket.Description,
-			&ticket.Status,
-			&ticket.Category,
-			&ticket.CreatedAt,
-			&closedAt,
-		); err != nil {
-			logger.LogError("Ошибка при сканировании строки тикета: %v", err)
-			continue
-		}
 
-		if closedAt.Valid {
-			closedAtTime := closedAt.Time
-			ticket.ClosedAt = &closedAtTime
+	// Фильтрация и сортировка
+	filter, ferr := parseTicketFilter(c, identity)
+	if ferr == nil {
+		var err error
+		if ferr, err = h.normalizeFieldFilter(ctx, &filter); err != nil {
+			logger.LogError("Ошибка при разборе фильтра по дополнительным полям: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении тикетов"})
+			return
 		}
-
-		tickets = append(tickets, ticket)
+	}
+	if ferr != nil {
+		respondFilterError(c, ferr)
+		return
+	}
+	list := "tickets:"
+	if deleted {
+		// Корзина по умолчанию показывает недавно удаленные первыми
+		filter.Deleted = true
+		if c.Query("sort") == "" {
+			filter.Sort = repository.TicketSort{Field: repository.TicketSortDeletedAt, Desc: true}
+		}
+		list = "trash:"
 	}
 
-	// Получаем общее количество тикетов для пагинации
-	var count int
-	var countErr error
+	// Пагинация: курсор привязан к списку, полю и направлению сортировки
+	order := list + filter.Sort.Field
+	if filter.Sort.Desc {
+		order = list + "-" + filter.Sort.Field
+	}
+	page, ok := parseListPage(c, 10, order, repository.TicketSortKind(filter.Sort.Field))
+	if !ok {
+		return
+	}
+	filter.Page = page.Page
 
-	if status != "" {
-		countErr = db.DB.QueryRow("SELECT COUNT(*) FROM tickets WHERE status = $1", status).Scan(&count)
-	} else {
-		countErr = db.DB.QueryRow("SELECT COUNT(*) FROM tickets").Scan(&count)
+	tickets, err := h.store.Tickets().List(ctx, filter)
+	if err != nil {
+		logger.LogError("Ошибка при получении тикетов: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении тикетов"})
+		return
 	}
 
-	if countErr != nil {
-		logger.LogError("Ошибка при подсчете тикетов: %v", countErr)
+	// Общее количество нужно только для пагинации по номеру страницы
+	count := 0
+	if !page.cursorMode() {
+		if count, err = h.store.Tickets().Count(ctx, filter); err != nil {
+			logger.LogError("Ошибка при подсчете тикетов: %v", err)
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"tickets": tickets,
-		"total":   count,
-		"page":    page,
-		"limit":   limit,
+	tickets, response := finishPage(page, tickets, count, func(ticket models.Ticket) repository.Cursor {
+		return repository.TicketCursor(ticket, filter.Sort.Field)
 	})
+	h.presentTicketList(c, tickets)
+
+	response["tickets"] = tickets
+	c.JSON(http.StatusOK, response)
 }
 
 // GetTicketById возвращает тикет по ID
-func GetTicketById(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+func (h *Handler) GetTicketById(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
 		return
 	}
 
-	var ticket models.Ticket
-	var closedAt sql.NullTime
-
-	err = db.DB.QueryRow(
-		"SELECT id, user_id, title, description, status, category, created_at, closed_at FROM tickets WHERE id = $1",
-		id,
-	).Scan(
-		&ticket.ID,
-		&ticket.UserID,
-		&ticket.Title,
-		&ticket.Description,
-		&ticket.Status,
-		&ticket.Category,
-		&ticket.CreatedAt,
-		&closedAt,
-	)
-
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
-		} else {
-			logger.LogError("Ошибка при получении тикета: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении тикета"})
-		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
 		return
 	}
 
-	if closedAt.Valid {
-		closedAtTime := closedAt.Time
-		ticket.ClosedAt = &closedAtTime
+	ticket, ok := h.loadTicket(c, identity, id, "Ошибка при получении тикета")
+	if !ok {
+		return
 	}
 
-	// Получаем сообщения тикета
-	rows, err := db.DB.Query(
-		"SELECT id, ticket_id, sender_type, sender_id, message, created_at FROM ticket_messages WHERE ticket_id = $1 ORDER BY created_at",
-		id,
-	)
+	// Получаем сообщения тикета; внутренние заметки видит только поддержка
+	messages, err := h.store.Messages().ListByTicket(ctx, id, repository.MessageFilter{WithoutNotes: !identity.IsStaff()})
 	if err != nil {
 		logger.LogError("Ошибка при получении сообщений тикета: %v", err)
 	}
-	defer rows.Close()
-
-	var messages []models.TicketMessage
-	for rows.Next() {
-		var message models.TicketMessage
-		if err := rows.Scan(
-			&message.ID,
-			&message.TicketID,
-			&message.SenderType,
-			&message.SenderID,
-			&message.Message,
-			&message.CreatedAt,
-		); err != nil {
-			logger.LogError("Ошибка при сканировании строки сообщения: %v", err)
-			continue
-		}
-		messages = append(messages, message)
-	}
 
 	// Получаем фотографии тикета
-	photoRows, err := db.DB.Query(
-		"SELECT id, ticket_id, sender_type, sender_id, file_path, file_id, message_id, created_at FROM ticket_photos WHERE ticket_id = $1",
-		id,
-	)
+	photos, err := h.store.Photos().ListByTicket(ctx, id)
 	if err != nil {
 		logger.LogError("Ошибка при получении фотографий тикета: %v", err)
 	}
-	defer photoRows.Close()
-
-	var photos []models.TicketPhoto
-	for photoRows.Next() {
-		var photo models.TicketPhoto
-		var messageID sql.NullInt32
-
-		if err := photoRows.Scan(
-			&photo.ID,
-			&photo.TicketID,
-			&photo.SenderType,
-			&photo.SenderID,
-			&photo.FilePath,
-			&photo.FileID,
-			&messageID,
-			&photo.CreatedAt,
-		); err != nil {
-			logger.LogError("Ошибка при сканировании строки фотографии: %v", err)
-			continue
-		}
 
-		if messageID.Valid {
-			msgID := int(messageID.Int32)
-			photo.MessageID = &msgID
-		}
+	// Получаем связанные тикеты
+	links, err := h.linkedTickets(c, identity, id)
+	if err != nil {
+		logger.LogError("Ошибка при получении связей тикета: %v", err)
+	}
 
-		photos = append(photos, photo)
+	// Получаем оценку клиента
+	rating, err := h.ticketRating(c, id)
+	if err != nil {
+		logger.LogError("Ошибка при получении оценки тикета: %v", err)
 	}
 
+	h.presentTickets(c, ticket)
+
 	c.JSON(http.StatusOK, gin.H{
 		"ticket":   ticket,
 		"messages": messages,
 		"photos":   photos,
+		"links":    links,
+		"rating":   rating,
 	})
 }
 
 // CreateTicket создает новый тикет
-func CreateTicket(c *gin.Context) {
+func (h *Handler) CreateTicket(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
 	var request models.NewTicketRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Клиент создает тикет только от своего имени
+	if !identity.IsStaff() {
+		if request.UserID != 0 && request.UserID != identity.UserID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Нельзя создать тикет от имени другого пользователя"})
+			return
+		}
+		request.UserID = identity.UserID
+	} else if request.UserID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не указан пользователь"})
+		return
+	}
+
+	// Приоритет назначает только поддержка
+	priority := models.DefaultPriority
+	if request.Priority != "" {
+		if !identity.IsStaff() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Изменять приоритет тикета может только поддержка"})
+			return
+		}
+		var ok bool
+		if priority, ok = models.ParsePriority(request.Priority); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Неизвестный приоритет тикета",
+				"priorities": models.Priorities,
+			})
+			return
+		}
+	}
+
 	// Проверяем существование пользователя
-	var exists bool
-	err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", request.UserID).Scan(&exists)
+	exists, err := h.store.Users().Exists(ctx, request.UserID)
 	if err != nil {
 		logger.LogError("Ошибка при проверке пользователя: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании тикета"})
@@ -229,17 +200,57 @@ func CreateTicket(c *gin.Context) {
 	}
 
 	// Если категория не указана, используем значение по умолчанию
+	request.Category = models.NormalizeCategory(request.Category)
 	if request.Category == "" {
-		request.Category = "спросить"
+		request.Category = models.DefaultCategory
 	}
 
-	// Создаем тикет
-	var ticketID int
-	err = db.DB.QueryRow(
-		"INSERT INTO tickets (user_id, title, description, status, category, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
-		request.UserID, request.Title, request.Description, "открыт", request.Category, time.Now(),
-	).Scan(&ticketID)
+	// Дополнительные поля проверяются по схеме категории
+	fields, err := h.store.CustomFields().List(ctx, request.Category)
+	if err != nil {
+		logger.LogError("Ошибка при получении дополнительных полей: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании тикета"})
+		return
+	}
+	customFields, fieldErrors := models.ValidateCustomFields(fields, request.CustomFields)
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Неверные дополнительные поля",
+			"fields": fieldErrors,
+		})
+		return
+	}
 
+	// Создаем тикет
+	ticket := models.Ticket{
+		UserID:       request.UserID,
+		Title:        request.Title,
+		Description:  request.Description,
+		Status:       models.StatusOpen,
+		Priority:     priority,
+		Category:     request.Category,
+		CreatedAt:    time.Now(),
+		CustomFields: customFields,
+	}
+	h.sla.Apply(&ticket)
+
+	// Тикет, начальные значения в истории и автоназначение сохраняются вместе
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		if err := tx.Tickets().Create(ctx, &ticket); err != nil {
+			return err
+		}
+		actor := actorOf(identity)
+		err := tx.History().Add(ctx,
+			models.NewHistoryEntry(ticket.ID, models.HistoryFieldStatus, "", ticket.Status, actor, ticket.CreatedAt),
+			models.NewHistoryEntry(ticket.ID, models.HistoryFieldPriority, "", ticket.Priority, actor, ticket.CreatedAt),
+			models.NewHistoryEntry(ticket.ID, models.HistoryFieldCategory, "", ticket.Category, actor, ticket.CreatedAt),
+		)
+		if err != nil {
+			return err
+		}
+		_, err = h.router.Assign(ctx, tx, &ticket)
+		return err
+	})
 	if err != nil {
 		logger.LogError("Ошибка при создании тикета: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании тикета"})
@@ -247,13 +258,21 @@ func CreateTicket(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message":   "Тикет создан успешно",
-		"ticket_id": ticketID,
+		"message":     "Тикет создан успешно",
+		"ticket_id":   ticket.ID,
+		"assignee_id": ticket.AssigneeID,
 	})
 }
 
 // UpdateTicket обновляет информацию о тикете
-func UpdateTicket(c *gin.Context) {
+func (h *Handler) UpdateTicket(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
@@ -266,93 +285,137 @@ func UpdateTicket(c *gin.Context) {
 		return
 	}
 
-	// Проверяем существование тикета
-	var exists bool
-	var userID int64
-	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM tickets WHERE id = $1), user_id FROM tickets WHERE id = $1", id).Scan(&exists, &userID)
-	if err != nil {
-		logger.LogError("Ошибка при проверке тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении тикета"})
+	// Проверяем существование тикета и доступ к нему
+	if _, ok := h.loadTicket(c, identity, id, "Ошибка при обновлении тикета"); !ok {
 		return
 	}
 
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
+	// Если нечего обновлять
+	if request.Status == "" && request.Category == "" && request.Priority == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нет данных для обновления"})
 		return
 	}
 
-	// Формируем запрос
-	query := "UPDATE tickets SET"
-	var params []interface{}
-	paramCount := 1
+	// Статус и приоритет меняет только поддержка
+	if request.Status != "" && !identity.IsStaff() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Изменять статус тикета может только поддержка"})
+		return
+	}
+	if request.Priority != "" && !identity.IsStaff() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Изменять приоритет тикета может только поддержка"})
+		return
+	}
 
+	// Статус принимается кодом или подписью и дальше используется только как код
 	if request.Status != "" {
-		query += " status = $" + strconv.Itoa(paramCount)
-		params = append(params, request.Status)
-		paramCount++
-
-		// Если статус изменился на "закрыт", устанавливаем время закрытия
-		if request.Status == "закрыт" {
-			if len(params) > 1 {
-				query += ","
-			}
-			query += " closed_at = $" + strconv.Itoa(paramCount)
-			params = append(params, time.Now())
-			paramCount++
+		status, ok := models.ParseStatus(request.Status)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":    "Неизвестный статус тикета",
+				"statuses": models.Statuses,
+			})
+			return
 		}
-	}
-
-	if request.Category != "" {
-		if len(params) > 0 {
-			query += ","
+		request.Status = status
+	}
+	if request.Priority != "" {
+		priority, ok := models.ParsePriority(request.Priority)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Неизвестный приоритет тикета",
+				"priorities": models.Priorities,
+			})
+			return
 		}
-		query += " category = $" + strconv.Itoa(paramCount)
-		params = append(params, request.Category)
-		paramCount++
+		request.Priority = priority
 	}
+	request.Category = models.NormalizeCategory(request.Category)
 
-	// Если нечего обновлять
-	if len(params) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Нет данных для обновления"})
-		return
+	// Уведомления получают клиенты в мессенджере, поэтому текст всегда на русском
+	statusMsg := fmt.Sprintf("Ваш тикет %d был обновлен", id)
+	if request.Status != "" {
+		statusMsg = notify.StatusNotification(id, request.Status, "")
 	}
 
-	query += " WHERE id = $" + strconv.Itoa(paramCount)
-	params = append(params, id)
+	// Изменение, история и уведомление сохраняются в одной транзакции;
+	// тикет блокируется, чтобы параллельные переходы статуса не конфликтовали
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		ticket, err := tx.Tickets().GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	// Выполняем запрос
-	_, err = db.DB.Exec(query, params...)
-	if err != nil {
-		logger.LogError("Ошибка при обновлении тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении тикета"})
-		return
-	}
+		now := time.Now()
+		actor := actorOf(identity)
+		var changes []models.TicketHistoryEntry
 
-	go func() {
-		statusMsg := ""
-		if request.Status != "" {
-			statusMsg = fmt.Sprintf("Статус вашего тикета %d изменен на '%s'", id, request.Status)
-		} else {
-			statusMsg = fmt.Sprintf("Ваш тикет %d был обновлен", id)
+		resolved := false
+		if request.Status != "" && request.Status != ticket.Status {
+			oldStatus := ticket.Status
+			if err := ticket.ApplyStatus(request.Status, now); err != nil {
+				return err
+			}
+			changes = append(changes, models.NewHistoryEntry(id, models.HistoryFieldStatus, oldStatus, ticket.Status, actor, now))
+			resolved = models.IsActiveStatus(oldStatus) && !models.IsActiveStatus(ticket.Status)
 		}
 
-		data := url.Values{}
-		data.Set("super_connect_token", "super_secret_key_2024")
-		data.Set("sender_id", "5259653323")
-		data.Set("message", statusMsg)
-		data.Set("accepter_id", strconv.Itoa(int(userID)))
-
-		resp, err := http.PostForm("http://localhost:8443/superconnect", data)
-		if err != nil {
-			logger.LogError("Ошибка при отправке уведомления: %v", err)
-			return
+		// Сроки SLA зависят от приоритета и категории и пересчитываются при их изменении
+		recalculate := false
+		if request.Priority != "" && request.Priority != ticket.Priority {
+			changes = append(changes, models.NewHistoryEntry(id, models.HistoryFieldPriority, ticket.Priority, request.Priority, actor, now))
+			ticket.Priority = request.Priority
+			recalculate = true
+		}
+		if request.Category != "" && request.Category != ticket.Category {
+			changes = append(changes, models.NewHistoryEntry(id, models.HistoryFieldCategory, ticket.Category, request.Category, actor, now))
+			ticket.Category = request.Category
+			if err := checkCategoryFields(ctx, tx, ticket); err != nil {
+				return err
+			}
+			recalculate = true
+		}
+		if recalculate {
+			h.sla.Apply(ticket)
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			logger.LogError("Ошибка при отправке уведомления, код ответа: %d", resp.StatusCode)
+		if err := tx.Tickets().Update(ctx, ticket); err != nil {
+			return err
+		}
+		if err := tx.History().Add(ctx, changes...); err != nil {
+			return err
 		}
-	}()
+		// Решение родительского тикета решает и проблему дочерних
+		if resolved {
+			if err := h.resolveChildren(ctx, tx, ticket, actor, now); err != nil {
+				return err
+			}
+		}
+		return h.outbox.Enqueue(ctx, tx, notify.Notification{
+			Event:       notify.EventTicketUpdated,
+			TicketID:    id,
+			RecipientID: ticket.UserID,
+			Message:     statusMsg,
+		})
+	})
+	if err != nil {
+		var transitionErr *models.TransitionError
+		var fieldsErr *customFieldsError
+		switch {
+		case errors.As(err, &transitionErr):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":            transitionErr.Error(),
+				"allowed_statuses": models.AllowedTransitions(transitionErr.From),
+			})
+		case errors.As(err, &fieldsErr):
+			respondCustomFieldsError(c, fieldsErr)
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
+		default:
+			logger.LogError("Ошибка при обновлении тикета: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении тикета"})
+		}
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":   "Тикет обновлен успешно",
@@ -360,71 +423,82 @@ func UpdateTicket(c *gin.Context) {
 	})
 }
 
-// DeleteTicket удаляет тикет
-func DeleteTicket(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
-		return
-	}
+// GetTicketDictionary возвращает коды статусов, приоритетов и категорий с подписями на языке запроса
+func (h *Handler) GetTicketDictionary(c *gin.Context) {
+	lang := requestLang(c)
 
-	// Проверяем существование тикета
-	var exists bool
-	err = db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM tickets WHERE id = $1)", id).Scan(&exists)
-	if err != nil {
-		logger.LogError("Ошибка при проверке тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении тикета"})
-		return
+	statuses := make([]gin.H, 0, len(models.Statuses))
+	for _, status := range models.Statuses {
+		statuses = append(statuses, gin.H{
+			"code":        status,
+			"label":       models.StatusLabel(status, lang),
+			"transitions": models.AllowedTransitions(status),
+		})
 	}
 
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
-		return
+	priorities := make([]gin.H, 0, len(models.Priorities))
+	for _, priority := range models.Priorities {
+		priorities = append(priorities, gin.H{
+			"code":  priority,
+			"label": models.PriorityLabel(priority, lang),
+		})
 	}
 
-	// Удаляем связанные сообщения и фотографии
-	tx, err := db.DB.Begin()
-	if err != nil {
-		logger.LogError("Ошибка при создании транзакции: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении тикета"})
-		return
+	categories := make([]gin.H, 0, len(models.Categories))
+	for _, category := range models.Categories {
+		categories = append(categories, gin.H{
+			"code":  category,
+			"label": models.CategoryLabel(category, lang),
+		})
 	}
 
-	// Удаляем фотографии
-	_, err = tx.Exec("DELETE FROM ticket_photos WHERE ticket_id = $1", id)
-	if err != nil {
-		tx.Rollback()
-		logger.LogError("Ошибка при удалении фотографий тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении тикета"})
-		return
-	}
+	c.JSON(http.StatusOK, gin.H{
+		"lang":             lang,
+		"statuses":         statuses,
+		"priorities":       priorities,
+		"categories":       categories,
+		"default_priority": models.DefaultPriority,
+		"default_category": models.DefaultCategory,
+	})
+}
 
-	// Удаляем сообщения
-	_, err = tx.Exec("DELETE FROM ticket_messages WHERE ticket_id = $1", id)
-	if err != nil {
-		tx.Rollback()
-		logger.LogError("Ошибка при удалении сообщений тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении тикета"})
+// DeleteTicket переносит тикет в корзину; восстановить его можно до очистки корзины
+func (h *Handler) DeleteTicket(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
 		return
 	}
 
-	// Удаляем сам тикет
-	_, err = tx.Exec("DELETE FROM tickets WHERE id = $1", id)
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		tx.Rollback()
-		logger.LogError("Ошибка при удалении тикета: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении тикета"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
 		return
 	}
 
-	if err = tx.Commit(); err != nil {
-		logger.LogError("Ошибка при фиксации транзакции: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении тикета"})
+	// Сообщения, фотографии и история остаются на месте до безвозвратного удаления
+	now := time.Now()
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		if err := tx.Tickets().SoftDelete(ctx, id, now, &identity.UserID); err != nil {
+			return err
+		}
+		entry := models.NewHistoryEntry(id, models.HistoryFieldDeleted, "", "true", actorOf(identity), now)
+		return tx.History().Add(ctx, entry)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Тикет не найден"})
+		} else {
+			logger.LogError("Ошибка при удалении тикета: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении тикета"})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":   "Тикет успешно удален",
-		"ticket_id": id,
+		"message":    "Тикет перемещен в корзину",
+		"ticket_id":  id,
+		"deleted_at": now,
 	})
 }
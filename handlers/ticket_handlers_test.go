@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"support_front_api/auth"
+	"support_front_api/config"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository/memory"
+	"support_front_api/routing"
+	"support_front_api/sla"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testCustomerID = 5
+	testSupportID  = 100
+)
+
+// testServer — обработчики тикетов поверх хранилища в памяти
+type testServer struct {
+	store  *memory.Store
+	router *gin.Engine
+	issuer *auth.Issuer
+}
+
+func newTestServer(t *testing.T, routingCfg config.RoutingConfig, slaCfg config.SLAConfig) *testServer {
+	t.Helper()
+
+	if err := logger.InitLogger(filepath.Join(t.TempDir(), "app.log")); err != nil {
+		t.Fatal(err)
+	}
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore()
+	ticketRouter, err := routing.NewRouter(routingCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies, err := sla.NewPolicies(slaCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox := notify.NewOutbox([]notify.Channel{{Name: "superconnect", Notifier: notify.NewSuperconnect(config.SuperconnectConfig{})}})
+	h := NewHandler(store, outbox, ticketRouter, policies)
+
+	issuer := auth.NewIssuer("test-secret", time.Hour)
+	router := gin.New()
+	api := router.Group("/api", issuer.Middleware())
+	api.POST("/tickets", h.CreateTicket)
+	api.PUT("/tickets/:id", h.UpdateTicket)
+
+	ctx := context.Background()
+	if err := store.Users().Create(ctx, &models.User{ID: testCustomerID, FullName: "Анна"}); err != nil {
+		t.Fatal(err)
+	}
+
+	return &testServer{store: store, router: router, issuer: issuer}
+}
+
+// do выполняет запрос от имени пользователя с ролью role и разбирает JSON-ответ
+func (s *testServer) do(t *testing.T, method, path, role string, userID int64, body string) (int, map[string]interface{}) {
+	t.Helper()
+
+	token, _, err := s.issuer.Issue(auth.Identity{UserID: userID, Role: role})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("ответ не JSON: %s", w.Body.String())
+	}
+	return w.Code, response
+}
+
+func TestCreateTicketRoutingAndSLA(t *testing.T) {
+	s := newTestServer(t,
+		config.RoutingConfig{
+			Strategy: routing.StrategyLeastOpen,
+			Categories: map[string]config.CategoryRoutingConfig{
+				"billing": {Strategy: routing.StrategySkills},
+			},
+		},
+		config.SLAConfig{Policies: []config.SLAPolicyConfig{
+			{FirstResponseMinutes: 240, ResolutionMinutes: 2880},
+			{Priority: models.PriorityHigh, FirstResponseMinutes: 60, ResolutionMinutes: 480},
+			{Priority: models.PriorityHigh, Category: "billing", FirstResponseMinutes: 15, ResolutionMinutes: 120},
+		}},
+	)
+
+	ctx := context.Background()
+	agents := []models.Agent{
+		{ID: 10, FullName: "Иван", IsActive: true, OnShift: true},
+		{ID: 11, FullName: "Мария", IsActive: true, OnShift: true, Skills: []string{"billing"}},
+		{ID: 12, FullName: "Петр", IsActive: true, OnShift: false, Skills: []string{"billing"}},
+	}
+	for i := range agents {
+		if err := s.store.Agents().Create(ctx, &agents[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		role          string
+		userID        int64
+		body          string
+		wantStatus    int
+		wantAssignee  int64
+		firstResponse time.Duration
+		resolution    time.Duration
+	}{
+		{
+			name:          "навык категории",
+			role:          auth.RoleCustomer,
+			userID:        testCustomerID,
+			body:          `{"title": "Двойное списание", "description": "...", "category": "billing"}`,
+			wantStatus:    http.StatusCreated,
+			wantAssignee:  11,
+			firstResponse: 240 * time.Minute,
+			resolution:    2880 * time.Minute,
+		},
+		{
+			name:          "наименее загруженный",
+			role:          auth.RoleSupport,
+			userID:        testSupportID,
+			body:          `{"user_id": 5, "title": "Не входит", "description": "...", "priority": "high"}`,
+			wantStatus:    http.StatusCreated,
+			wantAssignee:  10,
+			firstResponse: 60 * time.Minute,
+			resolution:    480 * time.Minute,
+		},
+		{
+			name:          "политика приоритета и категории",
+			role:          auth.RoleSupport,
+			userID:        testSupportID,
+			body:          `{"user_id": 5, "title": "Возврат", "description": "...", "priority": "high", "category": "billing"}`,
+			wantStatus:    http.StatusCreated,
+			wantAssignee:  11,
+			firstResponse: 15 * time.Minute,
+			resolution:    120 * time.Minute,
+		},
+		{
+			name:       "клиент не задает приоритет",
+			role:       auth.RoleCustomer,
+			userID:     testCustomerID,
+			body:       `{"title": "Срочно", "description": "...", "priority": "urgent"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "неизвестный приоритет",
+			role:       auth.RoleSupport,
+			userID:     testSupportID,
+			body:       `{"user_id": 5, "title": "Тест", "description": "...", "priority": "asap"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "клиент от имени другого пользователя",
+			role:       auth.RoleCustomer,
+			userID:     testCustomerID,
+			body:       `{"user_id": 6, "title": "Тест", "description": "..."}`,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := s.do(t, http.MethodPost, "/api/tickets", tt.role, tt.userID, tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("код %d, ожидался %d: %v", status, tt.wantStatus, response)
+			}
+			if status != http.StatusCreated {
+				return
+			}
+
+			ticket, err := s.store.Tickets().GetByID(ctx, int(response["ticket_id"].(float64)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ticket.AssigneeID == nil || *ticket.AssigneeID != tt.wantAssignee {
+				t.Errorf("ответственный %v, ожидался %d", models.AssigneeValue(ticket.AssigneeID), tt.wantAssignee)
+			}
+			if ticket.FirstResponseDue == nil || ticket.FirstResponseDue.Sub(ticket.CreatedAt) != tt.firstResponse {
+				t.Errorf("срок первого ответа %v, ожидалось через %v после %v", ticket.FirstResponseDue, tt.firstResponse, ticket.CreatedAt)
+			}
+			if ticket.ResolutionDue == nil || ticket.ResolutionDue.Sub(ticket.CreatedAt) != tt.resolution {
+				t.Errorf("срок решения %v, ожидалось через %v после %v", ticket.ResolutionDue, tt.resolution, ticket.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestCreateTicketWithoutRouting(t *testing.T) {
+	s := newTestServer(t, config.RoutingConfig{}, config.SLAConfig{})
+
+	status, response := s.do(t, http.MethodPost, "/api/tickets", auth.RoleCustomer, testCustomerID,
+		`{"title": "Вопрос", "description": "..."}`)
+	if status != http.StatusCreated {
+		t.Fatalf("код %d: %v", status, response)
+	}
+	if response["assignee_id"] != nil {
+		t.Errorf("тикет назначен без стратегии: %v", response["assignee_id"])
+	}
+
+	ticket, err := s.store.Tickets().GetByID(context.Background(), int(response["ticket_id"].(float64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Category != models.DefaultCategory || ticket.Priority != models.DefaultPriority {
+		t.Errorf("категория %q и приоритет %q вместо значений по умолчанию", ticket.Category, ticket.Priority)
+	}
+	if ticket.FirstResponseDue != nil || ticket.ResolutionDue != nil {
+		t.Errorf("сроки SLA без политик: %v, %v", ticket.FirstResponseDue, ticket.ResolutionDue)
+	}
+}
+
+func TestUpdateTicketCategoryRevalidatesCustomFields(t *testing.T) {
+	s := newTestServer(t, config.RoutingConfig{}, config.SLAConfig{})
+
+	ctx := context.Background()
+	for _, field := range []models.CustomField{
+		{Category: "billing", Key: "order_number", Label: "Номер заказа", Type: models.FieldTypeString, Required: true},
+		{Category: "delivery", Key: "order_number", Label: "Номер заказа", Type: models.FieldTypeString},
+		{Category: "technical", Key: "serial", Label: "Серийный номер", Type: models.FieldTypeString, Required: true},
+	} {
+		field := field
+		if _, err := s.store.CustomFields().Create(ctx, &field); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	status, response := s.do(t, http.MethodPost, "/api/tickets", auth.RoleCustomer, testCustomerID,
+		`{"title": "Заказ", "description": "...", "category": "billing", "custom_fields": {"order_number": "A-1"}}`)
+	if status != http.StatusCreated {
+		t.Fatalf("код %d: %v", status, response)
+	}
+	path := "/api/tickets/" + strconv.Itoa(int(response["ticket_id"].(float64)))
+
+	status, response = s.do(t, http.MethodPut, path, auth.RoleSupport, testSupportID, `{"category": "technical"}`)
+	if status != http.StatusBadRequest {
+		t.Fatalf("смена категории с неподходящими полями: код %d: %v", status, response)
+	}
+	fields, _ := response["fields"].(map[string]interface{})
+	if _, ok := fields["serial"]; !ok {
+		t.Errorf("нет ошибки обязательного поля: %v", response)
+	}
+	if _, ok := fields["order_number"]; !ok {
+		t.Errorf("нет ошибки лишнего поля: %v", response)
+	}
+
+	status, response = s.do(t, http.MethodPut, path, auth.RoleSupport, testSupportID, `{"category": "delivery"}`)
+	if status != http.StatusOK {
+		t.Fatalf("смена категории с подходящими полями: код %d: %v", status, response)
+	}
+}
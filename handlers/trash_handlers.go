@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"support_front_api/trash"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrash возвращает тикеты из корзины; фильтры, сортировка и пагинация те же, что у списка тикетов
+func (h *Handler) GetTrash(c *gin.Context) {
+	h.listTickets(c, true)
+}
+
+// RestoreTicket возвращает тикет из корзины
+func (h *Handler) RestoreTicket(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+
+	var ticket *models.Ticket
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		if err := tx.Tickets().Restore(ctx, id); err != nil {
+			return err
+		}
+		entry := models.NewHistoryEntry(id, models.HistoryFieldDeleted, "true", "", actorOf(identity), time.Now())
+		if err := tx.History().Add(ctx, entry); err != nil {
+			return err
+		}
+
+		ticket, err = tx.Tickets().GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Тикет в корзине не найден"})
+		} else {
+			logger.LogError("Ошибка при восстановлении тикета: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при восстановлении тикета"})
+		}
+		return
+	}
+
+	h.presentTickets(c, ticket)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Тикет восстановлен",
+		"ticket":  ticket,
+	})
+}
+
+// PurgeTicket безвозвратно удаляет тикет из корзины вместе с сообщениями, историей и файлами фотографий
+func (h *Handler) PurgeTicket(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID тикета"})
+		return
+	}
+
+	var files []string
+	err = h.store.InTx(ctx, func(tx repository.Store) error {
+		// Удалить навсегда можно только тикет, уже перенесенный в корзину
+		if _, err := tx.Tickets().GetDeleted(ctx, id); err != nil {
+			return err
+		}
+		files, err = trash.PurgeTicket(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Тикет в корзине не найден"})
+		} else {
+			logger.LogError("Ошибка при безвозвратном удалении тикета: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении тикета"})
+		}
+		return
+	}
+
+	trash.RemoveFiles(files)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Тикет удален безвозвратно",
+		"ticket_id": id,
+	})
+}
@@ -1,84 +1,59 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
-	"support_front_api/db"
 	"support_front_api/logger"
 	"support_front_api/models"
+	"support_front_api/repository"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetUsers возвращает список пользователей
-func GetUsers(c *gin.Context) {
+func (h *Handler) GetUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// Пагинация
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	offset := (page - 1) * limit
+	page, ok := parseListPage(c, 20, "users", repository.KeyNone)
+	if !ok {
+		return
+	}
 
 	// Получаем пользователей из базы данных
-	rows, err := db.DB.Query(
-		"SELECT id, full_name, phone, location_lat, location_lng, birth_date, is_registered, registered_at FROM users ORDER BY id LIMIT $1 OFFSET $2",
-		limit, offset,
-	)
+	users, err := h.store.Users().List(ctx, page.Page)
 	if err != nil {
 		logger.LogError("Ошибка при получении пользователей: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении пользователей"})
 		return
 	}
-	defer rows.Close()
-
-	var users []models.User
-	for rows.Next() {
-		var user models.User
-		var birthDate sql.NullTime
-		var registeredAt sql.NullTime
-
-		if err := rows.Scan(
-			&user.ID,
-			&user.FullName,
-			&user.Phone,
-			&user.LocationLat,
-			&user.LocationLng,
-			&birthDate,
-			&user.IsRegistered,
-			&registeredAt,
-		); err != nil {
-			logger.LogError("Ошибка при сканировании пользователя: %v", err)
-			continue
-		}
-
-		if birthDate.Valid {
-			user.BirthDate = birthDate.Time
-		}
-
-		if registeredAt.Valid {
-			regAt := registeredAt.Time
-			user.RegisteredAt = &regAt
-		}
-
-		users = append(users, user)
-	}
 
 	// Получаем общее количество пользователей
-	var count int
-	if err := db.DB.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil {
-		logger.LogError("Ошибка при подсчете пользователей: %v", err)
+	count := 0
+	if !page.cursorMode() {
+		if count, err = h.store.Users().Count(ctx); err != nil {
+			logger.LogError("Ошибка при подсчете пользователей: %v", err)
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"users": users,
-		"total": count,
-		"page":  page,
-		"limit": limit,
+	users, response := finishPage(page, users, count, func(user models.User) repository.Cursor {
+		return repository.Cursor{ID: user.ID}
 	})
+	response["users"] = users
+	c.JSON(http.StatusOK, response)
 }
 
 // GetUserById возвращает пользователя по ID
-func GetUserById(c *gin.Context) {
+func (h *Handler) GetUserById(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
 	// Получаем ID пользователя
 	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -86,27 +61,16 @@ func GetUserById(c *gin.Context) {
 		return
 	}
 
-	// Получаем пользователя из базы данных
-	var user models.User
-	var birthDate sql.NullTime
-	var registeredAt sql.NullTime
-
-	err = db.DB.QueryRow(
-		"SELECT id, full_name, phone, location_lat, location_lng, birth_date, is_registered, registered_at FROM users WHERE id = $1",
-		userID,
-	).Scan(
-		&user.ID,
-		&user.FullName,
-		&user.Phone,
-		&user.LocationLat,
-		&user.LocationLng,
-		&birthDate,
-		&user.IsRegistered,
-		&registeredAt,
-	)
+	// Клиент может получить только свои данные
+	if !canAccessUser(identity, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ к пользователю запрещен"})
+		return
+	}
 
+	// Получаем пользователя из базы данных
+	user, err := h.store.Users().GetByID(ctx, userID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, repository.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
 		} else {
 			logger.LogError("Ошибка при получении пользователя: %v", err)
@@ -115,51 +79,11 @@ func GetUserById(c *gin.Context) {
 		return
 	}
 
-	if birthDate.Valid {
-		user.BirthDate = birthDate.Time
-	}
-
-	if registeredAt.Valid {
-		regAt := registeredAt.Time
-		user.RegisteredAt = &regAt
-	}
-
 	// Получаем тикеты пользователя
-	rows, err := db.DB.Query(
-		"SELECT id, user_id, title, description, status, category, created_at, closed_at FROM tickets WHERE user_id = $1 ORDER BY created_at DESC",
-		userID,
-	)
+	tickets, err := h.store.Tickets().List(ctx, repository.TicketFilter{UserID: &userID})
 	if err != nil {
 		logger.LogError("Ошибка при получении тикетов пользователя: %v", err)
 	}
-	defer rows.Close()
-
-	var tickets []models.Ticket
-	for rows.Next() {
-		var ticket models.Ticket
-		var closedAt sql.NullTime
-
-		if err := rows.Scan(
-			&ticket.ID,
-			&ticket.UserID,
-			&ticket.Title,
-			&ticket.Description,
-			&ticket.Status,
-			&ticket.Category,
-			&ticket.CreatedAt,
-			&closedAt,
-		); err != nil {
-			logger.LogError("Ошибка при сканировании тикета: %v", err)
-			continue
-		}
-
-		if closedAt.Valid {
-			closedAtTime := closedAt.Time
-			ticket.ClosedAt = &closedAtTime
-		}
-
-		tickets = append(tickets, ticket)
-	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"user":    user,
@@ -168,7 +92,9 @@ func GetUserById(c *gin.Context) {
 }
 
 // CreateUser создает нового пользователя
-func CreateUser(c *gin.Context) {
+func (h *Handler) CreateUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -176,8 +102,8 @@ func CreateUser(c *gin.Context) {
 	}
 
 	// Проверяем, существует ли пользователь с таким ID
-	var exists bool
-	if err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", user.ID).Scan(&exists); err != nil {
+	exists, err := h.store.Users().Exists(ctx, user.ID)
+	if err != nil {
 		logger.LogError("Ошибка при проверке существования пользователя: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании пользователя"})
 		return
@@ -197,19 +123,7 @@ func CreateUser(c *gin.Context) {
 	}
 
 	// Создаем пользователя
-	_, err := db.DB.Exec(
-		"INSERT INTO users (id, full_name, phone, location_lat, location_lng, birth_date, is_registered, registered_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
-		user.ID,
-		user.FullName,
-		user.Phone,
-		user.LocationLat,
-		user.LocationLng,
-		user.BirthDate,
-		user.IsRegistered,
-		user.RegisteredAt,
-	)
-
-	if err != nil {
+	if err := h.store.Users().Create(ctx, &user); err != nil {
 		logger.LogError("Ошибка при создании пользователя: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании пользователя"})
 		return
@@ -222,7 +136,14 @@ func CreateUser(c *gin.Context) {
 }
 
 // UpdateUser обновляет информацию о пользователе
-func UpdateUser(c *gin.Context) {
+func (h *Handler) UpdateUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	identity, ok := currentIdentity(c)
+	if !ok {
+		return
+	}
+
 	// Получаем ID пользователя
 	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -230,53 +151,24 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
-	// Проверяем существование пользователя
-	var exists bool
-	if err := db.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists); err != nil {
-		logger.LogError("Ошибка при проверке существования пользователя: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении пользователя"})
-		return
-	}
-
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+	// Клиент может изменить только свои данные
+	if !canAccessUser(identity, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Доступ к пользователю запрещен"})
 		return
 	}
 
 	// Получаем текущие данные пользователя
-	var currentUser models.User
-	var birthDate sql.NullTime
-	var registeredAt sql.NullTime
-
-	err = db.DB.QueryRow(
-		"SELECT id, full_name, phone, location_lat, location_lng, birth_date, is_registered, registered_at FROM users WHERE id = $1",
-		userID,
-	).Scan(
-		&currentUser.ID,
-		&currentUser.FullName,
-		&currentUser.Phone,
-		&currentUser.LocationLat,
-		&currentUser.LocationLng,
-		&birthDate,
-		&currentUser.IsRegistered,
-		&registeredAt,
-	)
-
+	currentUser, err := h.store.Users().GetByID(ctx, userID)
 	if err != nil {
-		logger.LogError("Ошибка при получении текущих данных пользователя: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении пользователя"})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+		} else {
+			logger.LogError("Ошибка при получении текущих данных пользователя: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении пользователя"})
+		}
 		return
 	}
 
-	if birthDate.Valid {
-		currentUser.BirthDate = birthDate.Time
-	}
-
-	if registeredAt.Valid {
-		regAt := registeredAt.Time
-		currentUser.RegisteredAt = &regAt
-	}
-
 	// Получаем новые данные
 	var updateUser models.User
 	if err := c.ShouldBindJSON(&updateUser); err != nil {
@@ -310,19 +202,7 @@ func UpdateUser(c *gin.Context) {
 	}
 
 	// Обновляем данные пользователя
-	_, err = db.DB.Exec(
-		"UPDATE users SET full_name = $1, phone = $2, location_lat = $3, location_lng = $4, birth_date = $5, is_registered = $6, registered_at = $7 WHERE id = $8",
-		currentUser.FullName,
-		currentUser.Phone,
-		currentUser.LocationLat,
-		currentUser.LocationLng,
-		currentUser.BirthDate,
-		currentUser.IsRegistered,
-		currentUser.RegisteredAt,
-		userID,
-	)
-
-	if err != nil {
+	if err := h.store.Users().Update(ctx, currentUser); err != nil {
 		logger.LogError("Ошибка при обновлении пользователя: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обновлении пользователя"})
 		return
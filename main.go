@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"support_front_api/auth"
+	"support_front_api/autoclose"
 	"support_front_api/config"
 	"support_front_api/db"
 	"support_front_api/handlers"
 	"support_front_api/logger"
+	"support_front_api/notify"
+	"support_front_api/repository/postgres"
+	"support_front_api/routing"
+	"support_front_api/sla"
+	"support_front_api/trash"
+	"syscall"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -18,6 +30,10 @@ import (
 func main() {
 	// Парсим флаги командной строки
 	configPath := flag.String("config", "config/.config", "путь к файлу конфигурации")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Использование: %s [-config путь] [migrate up|down [N]|status]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	// Загружаем или создаем конфигурацию
@@ -29,8 +45,13 @@ func main() {
 			log.Fatalf("Ошибка при создании директории для конфигурации: %v", err)
 		}
 
-		// Создаем конфигурацию по умолчанию
+		// Создаем конфигурацию по умолчанию со случайным JWT-секретом
 		cfg = config.DefaultConfig()
+		secret, err := config.GenerateSecret()
+		if err != nil {
+			log.Fatalf("Ошибка при генерации JWT-секрета: %v", err)
+		}
+		cfg.JWTSecret = secret
 		if err := config.SaveConfig(cfg, *configPath); err != nil {
 			log.Fatalf("Ошибка при сохранении конфигурации: %v", err)
 		}
@@ -50,6 +71,12 @@ func main() {
 		log.Fatalf("Ошибка при инициализации логирования: %v", err)
 	}
 
+	// С пустым секретом или заглушкой токены может подделать кто угодно
+	if cfg.InsecureJWTSecret() {
+		logger.LogError("JWT-секрет не задан или равен значению по умолчанию: укажите jwt_secret в %s", *configPath)
+		log.Fatalf("JWT-секрет не задан или равен значению по умолчанию: укажите jwt_secret в %s", *configPath)
+	}
+
 	// Инициализируем соединение с базой данных
 	if err := db.InitDB(cfg); err != nil {
 		logger.LogError("Ошибка при инициализации базы данных: %v", err)
@@ -57,6 +84,19 @@ func main() {
 	}
 	defer db.CloseDB()
 
+	// Режим миграций: выполняем команду и завершаем работу без запуска сервера
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "migrate" {
+			flag.Usage()
+			log.Fatalf("Неизвестная команда: %s", args[0])
+		}
+		if err := runMigrate(args[1:]); err != nil {
+			logger.LogError("Ошибка при выполнении миграций: %v", err)
+			log.Fatalf("Ошибка при выполнении миграций: %v", err)
+		}
+		return
+	}
+
 	// Создаем директорию для загрузок
 	uploadsDir := "./uploads/"
 	if err := os.MkdirAll(uploadsDir, 0755); err != nil {
@@ -64,6 +104,64 @@ func main() {
 		log.Fatalf("Ошибка при создании директории для загрузок: %v", err)
 	}
 
+	// Контекст фоновых задач отменяется при остановке процесса
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store := postgres.NewStore(db.DB)
+
+	// Уведомления пишутся в очередь вместе с изменениями и доставляются диспетчером
+	channels := notify.ChannelsFromConfig(cfg.Notifications)
+	dispatcher := notify.NewDispatcher(store, channels, cfg.Notifications.Outbox)
+	go dispatcher.Run(ctx)
+
+	// Автоматическое назначение новых тикетов
+	ticketRouter, err := routing.NewRouter(cfg.Routing)
+	if err != nil {
+		logger.LogError("Ошибка в настройках назначения тикетов: %v", err)
+		log.Fatalf("Ошибка в настройках назначения тикетов: %v", err)
+	}
+
+	// Сроки SLA по приоритету и категории
+	slaPolicies, err := sla.NewPolicies(cfg.SLA)
+	if err != nil {
+		logger.LogError("Ошибка в настройках SLA: %v", err)
+		log.Fatalf("Ошибка в настройках SLA: %v", err)
+	}
+
+	outbox := notify.NewOutbox(channels)
+
+	// Эскалация по срокам SLA; advisory-блокировка позволяет запускать несколько экземпляров
+	escalator, err := sla.NewEscalator(store, slaPolicies, outbox, cfg.Escalation)
+	if err != nil {
+		logger.LogError("Ошибка в настройках эскалации SLA: %v", err)
+		log.Fatalf("Ошибка в настройках эскалации SLA: %v", err)
+	}
+	go escalator.Run(ctx)
+
+	// Напоминания и автозакрытие тикетов, в которых клиент не отвечает поддержке
+	closer, err := autoclose.NewCloser(store, outbox, cfg.AutoClose)
+	if err != nil {
+		logger.LogError("Ошибка в настройках автозакрытия тикетов: %v", err)
+		log.Fatalf("Ошибка в настройках автозакрытия тикетов: %v", err)
+	}
+	go closer.Run(ctx)
+
+	// Безвозвратное удаление тикетов из корзины по сроку хранения
+	purger, err := trash.NewPurger(store, cfg.Trash)
+	if err != nil {
+		logger.LogError("Ошибка в настройках корзины: %v", err)
+		log.Fatalf("Ошибка в настройках корзины: %v", err)
+	}
+	go purger.Run(ctx)
+
+	// Обработчики работают с хранилищем через интерфейсы репозиториев
+	h := handlers.NewHandler(store, outbox, ticketRouter, slaPolicies)
+
+	// JWT-аутентификация
+	issuer := auth.NewIssuer(cfg.JWTSecret, cfg.JWTTTL())
+	authHandler := handlers.NewAuthHandler(issuer, cfg.ServiceClients)
+
 	// Инициализация роутера Gin
 	router := gin.Default()
 
@@ -77,9 +175,6 @@ func main() {
 	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
 	router.Use(cors.New(corsConfig))
 
-	// Статические файлы
-	router.Static("/uploads", "../uploads")
-	router.Static("/api/uploads", "../uploads")
 	// Базовый маршрут для проверки работы API
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -87,32 +182,91 @@ func main() {
 		})
 	})
 
+	// Выдача токенов сервисным клиентам
+	router.POST("/api/auth/token", authHandler.IssueToken)
+
 	// Группа маршрутов для тикетов
-	ticketsGroup := router.Group("/api/tickets")
+	ticketsGroup := router.Group("/api/tickets", issuer.Middleware())
 	{
-		ticketsGroup.GET("/", handlers.GetAllTickets)
-		ticketsGroup.GET("/:id", handlers.GetTicketById)
-		ticketsGroup.POST("/", handlers.CreateTicket)
-		ticketsGroup.PUT("/:id", handlers.UpdateTicket)
-		ticketsGroup.DELETE("/:id", handlers.DeleteTicket)
+		ticketsGroup.GET("/", h.GetAllTickets)
+		ticketsGroup.GET("/dictionary", h.GetTicketDictionary)
+		ticketsGroup.GET("/custom-fields", h.GetCustomFields)
+		ticketsGroup.GET("/:id", h.GetTicketById)
+		ticketsGroup.POST("/", h.CreateTicket)
+		ticketsGroup.PUT("/:id", h.UpdateTicket)
+		ticketsGroup.DELETE("/:id", auth.RequireRole(auth.RoleAdmin), h.DeleteTicket)
+		ticketsGroup.GET("/:id/history", auth.RequireRole(auth.RoleSupport), h.GetTicketHistory)
+		ticketsGroup.POST("/:id/assign", auth.RequireRole(auth.RoleSupport), h.AssignTicket)
+		ticketsGroup.POST("/:id/unassign", auth.RequireRole(auth.RoleSupport), h.UnassignTicket)
+		ticketsGroup.POST("/:id/merge", auth.RequireRole(auth.RoleSupport), h.MergeTickets)
+		ticketsGroup.POST("/:id/links", auth.RequireRole(auth.RoleSupport), h.CreateTicketLink)
+		ticketsGroup.DELETE("/:id/links/:link_id", auth.RequireRole(auth.RoleSupport), h.DeleteTicketLink)
+		ticketsGroup.POST("/:id/rating", h.RateTicket)
 
 		// Маршруты для сообщений в тикетах
-		ticketsGroup.POST("/:id/messages", handlers.AddMessage)
-		ticketsGroup.GET("/:id/messages", handlers.GetTicketMessages)
+		ticketsGroup.POST("/:id/messages", h.AddMessage)
+		ticketsGroup.GET("/:id/messages", h.GetTicketMessages)
+		ticketsGroup.POST("/:id/notes", auth.RequireRole(auth.RoleSupport), h.AddTicketNote)
+		ticketsGroup.POST("/:id/macro", auth.RequireRole(auth.RoleSupport), h.ApplyMacro)
 
 		// Маршруты для фотографий в тикетах
-		ticketsGroup.POST("/:id/photos", handlers.UploadTicketPhoto)
-		ticketsGroup.GET("/photos/:photo_id", handlers.GetTicketPhoto)
-		ticketsGroup.DELETE("/photos/:photo_id", handlers.DeleteTicketPhoto)
+		ticketsGroup.POST("/:id/photos", h.UploadTicketPhoto)
+		ticketsGroup.GET("/photos/:photo_id", h.GetTicketPhoto)
+		ticketsGroup.DELETE("/photos/:photo_id", h.DeleteTicketPhoto)
 	}
 
 	// Группа маршрутов для пользователей
-	usersGroup := router.Group("/api/users")
+	usersGroup := router.Group("/api/users", issuer.Middleware())
+	{
+		usersGroup.GET("/", auth.RequireRole(auth.RoleSupport), h.GetUsers)
+		usersGroup.GET("/:id", h.GetUserById)
+		usersGroup.POST("/", auth.RequireRole(auth.RoleSupport), h.CreateUser)
+		usersGroup.PUT("/:id", h.UpdateUser)
+	}
+
+	// Библиотека шаблонов ответов
+	cannedGroup := router.Group("/api/canned-responses", issuer.Middleware(), auth.RequireRole(auth.RoleSupport))
+	{
+		cannedGroup.GET("/", h.GetCannedResponses)
+		cannedGroup.POST("/", h.CreateCannedResponse)
+		cannedGroup.PUT("/:id", h.UpdateCannedResponse)
+		cannedGroup.DELETE("/:id", h.DeleteCannedResponse)
+	}
+
+	// Справочник сотрудников поддержки
+	agentsGroup := router.Group("/api/agents", issuer.Middleware(), auth.RequireRole(auth.RoleSupport))
+	{
+		agentsGroup.GET("/", h.GetAgents)
+		agentsGroup.GET("/:id", h.GetAgentById)
+		agentsGroup.POST("/", auth.RequireRole(auth.RoleAdmin), h.CreateAgent)
+		agentsGroup.PUT("/:id", auth.RequireRole(auth.RoleAdmin), h.UpdateAgent)
+		agentsGroup.DELETE("/:id", auth.RequireRole(auth.RoleAdmin), h.DeleteAgent)
+		agentsGroup.PUT("/:id/shift", h.SetAgentShift)
+	}
+
+	// Полнотекстовый поиск по тикетам и сообщениям
+	router.GET("/api/search", issuer.Middleware(), h.SearchTickets)
+
+	// Отчеты для поддержки
+	reportsGroup := router.Group("/api/reports", issuer.Middleware(), auth.RequireRole(auth.RoleSupport))
 	{
-		usersGroup.GET("/", handlers.GetUsers)
-		usersGroup.GET("/:id", handlers.GetUserById)
-		usersGroup.POST("/", handlers.CreateUser)
-		usersGroup.PUT("/:id", handlers.UpdateUser)
+		reportsGroup.GET("/first-response", h.GetFirstResponseReport)
+		reportsGroup.GET("/csat", h.GetCSATReport)
+	}
+
+	// Администрирование очереди уведомлений и корзины тикетов
+	adminGroup := router.Group("/api/admin", issuer.Middleware(), auth.RequireRole(auth.RoleAdmin))
+	{
+		adminGroup.GET("/notifications", h.GetNotifications)
+		adminGroup.POST("/notifications/:id/replay", h.ReplayNotification)
+
+		adminGroup.GET("/trash", h.GetTrash)
+		adminGroup.POST("/trash/:id/restore", h.RestoreTicket)
+		adminGroup.DELETE("/trash/:id", h.PurgeTicket)
+
+		adminGroup.POST("/custom-fields", h.CreateCustomField)
+		adminGroup.PUT("/custom-fields/:id", h.UpdateCustomField)
+		adminGroup.DELETE("/custom-fields/:id", h.DeleteCustomField)
 	}
 
 	// Запуск сервера
@@ -122,3 +276,51 @@ func main() {
 		log.Fatalf("Ошибка запуска сервера: %v", err)
 	}
 }
+
+// runMigrate выполняет команду migrate up|down [N]|status
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("не указана команда миграции: up, down или status")
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := db.MigrateUp(ctx, db.DB)
+		if err != nil {
+			return err
+		}
+		logger.LogInfo("Применено миграций: %d", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("неверное количество шагов отката: %s", args[1])
+			}
+			steps = n
+		}
+		reverted, err := db.MigrateDown(ctx, db.DB, steps)
+		if err != nil {
+			return err
+		}
+		logger.LogInfo("Откачено миграций: %d", reverted)
+	case "status":
+		statuses, err := db.GetMigrationStatus(ctx, db.DB)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			if s.Applied {
+				fmt.Printf("%04d_%s\tприменена %s\n", s.Version, s.Name, s.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("%04d_%s\tожидает\n", s.Version, s.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("неизвестная команда миграции: %s", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Agent представляет сотрудника поддержки. ID совпадает с user_id в токене сотрудника.
+type Agent struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	IsActive bool   `json:"is_active"`
+	// Skills — навыки для маршрутизации skills, обычно коды категорий
+	Skills []string `json:"skills"`
+	// OnShift — сотрудник на смене и получает новые тикеты
+	OnShift   bool      `json:"on_shift"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Available сообщает, можно ли назначать сотруднику тикеты
+func (a *Agent) Available() bool {
+	return a.IsActive && a.OnShift
+}
+
+// HasAnySkill сообщает, есть ли у сотрудника хотя бы один из навыков
+func (a *Agent) HasAnySkill(skills []string) bool {
+	for _, required := range skills {
+		for _, skill := range a.Skills {
+			if skill == required {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewAgentRequest представляет запрос на добавление сотрудника
+type NewAgentRequest struct {
+	ID       int64    `json:"id" binding:"required"`
+	FullName string   `json:"full_name" binding:"required"`
+	Email    string   `json:"email"`
+	Skills   []string `json:"skills"`
+}
+
+// UpdateAgentRequest представляет запрос на изменение сотрудника, пустые поля не меняются
+type UpdateAgentRequest struct {
+	FullName *string  `json:"full_name"`
+	Email    *string  `json:"email"`
+	IsActive *bool    `json:"is_active"`
+	Skills   []string `json:"skills"`
+}
+
+// AgentShiftRequest представляет запрос на начало или окончание смены
+type AgentShiftRequest struct {
+	OnShift *bool `json:"on_shift" binding:"required"`
+}
+
+// AssignTicketRequest представляет запрос на назначение ответственного.
+// Без agent_id тикет назначается на вызывающего.
+type AssignTicketRequest struct {
+	AgentID *int64 `json:"agent_id"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// CannedResponse — шаблон ответа из библиотеки поддержки. Общие шаблоны (без OwnerID)
+// видят все сотрудники, личные — только их автор.
+type CannedResponse struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	// Body — текст с переменными вида {{customer_name}} (см. TemplateVariables)
+	Body string `json:"body"`
+	// Category — код категории тикетов, для которых предназначен шаблон; пустая — для любых
+	Category  string    `json:"category"`
+	OwnerID   *int64    `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Shared сообщает, что шаблон общий
+func (r *CannedResponse) Shared() bool {
+	return r.OwnerID == nil
+}
+
+// Переменные шаблонов ответов
+const (
+	// TemplateCustomerName — имя клиента (User.FullName)
+	TemplateCustomerName = "customer_name"
+	TemplateTicketID     = "ticket_id"
+	TemplateTicketTitle  = "ticket_title"
+	// TemplateTicketCategory — подпись категории тикета на русском
+	TemplateTicketCategory = "ticket_category"
+	// TemplateAgentName — имя сотрудника, отправляющего ответ (Agent.FullName)
+	TemplateAgentName = "agent_name"
+)
+
+// TemplateVariables перечисляет переменные, доступные в шаблонах ответов
+var TemplateVariables = []string{
+	TemplateCustomerName,
+	TemplateTicketID,
+	TemplateTicketTitle,
+	TemplateTicketCategory,
+	TemplateAgentName,
+}
+
+// templatePattern находит переменные шаблона: {{имя}}, пробелы внутри скобок допускаются
+var templatePattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// UnknownTemplateVariables возвращает переменные шаблона, которых нет в TemplateVariables
+func UnknownTemplateVariables(body string) []string {
+	var unknown []string
+	for _, match := range templatePattern.FindAllStringSubmatch(body, -1) {
+		if !containsString(TemplateVariables, match[1]) && !containsString(unknown, match[1]) {
+			unknown = append(unknown, match[1])
+		}
+	}
+	return unknown
+}
+
+// RenderTemplate подставляет в шаблон значения переменных; переменные без значения остаются как есть
+func RenderTemplate(body string, values map[string]string) string {
+	return templatePattern.ReplaceAllStringFunc(body, func(variable string) string {
+		name := templatePattern.FindStringSubmatch(variable)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return variable
+	})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCannedResponseRequest представляет запрос на создание шаблона ответа.
+// Общий шаблон (shared) может создать только администратор.
+type NewCannedResponseRequest struct {
+	Title    string `json:"title" binding:"required"`
+	Body     string `json:"body" binding:"required"`
+	Category string `json:"category"`
+	Shared   bool   `json:"shared"`
+}
+
+// UpdateCannedResponseRequest представляет запрос на изменение шаблона, пустые поля не меняются
+type UpdateCannedResponseRequest struct {
+	Title    *string `json:"title"`
+	Body     *string `json:"body"`
+	Category *string `json:"category"`
+}
+
+// MacroRequest представляет запрос на применение макроса: ответ клиенту по шаблону
+// и, при необходимости, смену статуса и категории тикета
+type MacroRequest struct {
+	CannedResponseID int64  `json:"canned_response_id" binding:"required"`
+	Status           string `json:"status"`
+	Category         string `json:"category"`
+}
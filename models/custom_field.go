@@ -0,0 +1,162 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Типы дополнительных полей тикета
+const (
+	FieldTypeString  = "string"
+	FieldTypeNumber  = "number"
+	FieldTypeBoolean = "boolean"
+	// FieldTypeDate — дата ГГГГ-ММ-ДД
+	FieldTypeDate = "date"
+	// FieldTypeEnum — одно значение из Options
+	FieldTypeEnum = "enum"
+)
+
+// FieldTypes перечисляет типы дополнительных полей
+var FieldTypes = []string{FieldTypeString, FieldTypeNumber, FieldTypeBoolean, FieldTypeDate, FieldTypeEnum}
+
+// maxFieldStringLength ограничивает длину строкового значения поля
+const maxFieldStringLength = 500
+
+// fieldKeyPattern — ключ поля: латиница в нижнем регистре, цифры и _, с буквы
+var fieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// CustomField описывает дополнительное поле тикетов категории: номер заказа,
+// серийный номер устройства, адрес. Ключ поля уникален в пределах категории.
+type CustomField struct {
+	ID       int64  `json:"id"`
+	Category string `json:"category"`
+	Key      string `json:"key"`
+	Label    string `json:"label"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	// Options — допустимые значения поля типа enum
+	Options   []string  `json:"options,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidFieldType проверяет тип дополнительного поля
+func ValidFieldType(fieldType string) bool {
+	return containsString(FieldTypes, fieldType)
+}
+
+// ValidFieldKey проверяет ключ дополнительного поля
+func ValidFieldKey(key string) bool {
+	return fieldKeyPattern.MatchString(key)
+}
+
+// Normalize проверяет значение по типу поля и приводит его к хранимому виду: строка, число
+// или логическое значение. Число и логическое значение можно передать и строкой,
+// как в параметрах запроса.
+func (f *CustomField) Normalize(value interface{}) (interface{}, error) {
+	text, isText := value.(string)
+	if isText {
+		text = strings.TrimSpace(text)
+	}
+
+	switch f.Type {
+	case FieldTypeNumber:
+		if number, ok := value.(float64); ok {
+			return number, nil
+		}
+		// NaN и бесконечность не записываются в JSON
+		if number, err := strconv.ParseFloat(text, 64); isText && err == nil && !math.IsNaN(number) && !math.IsInf(number, 0) {
+			return number, nil
+		}
+		return nil, fmt.Errorf("ожидается число")
+	case FieldTypeBoolean:
+		if flag, ok := value.(bool); ok {
+			return flag, nil
+		}
+		if flag, err := strconv.ParseBool(text); isText && err == nil {
+			return flag, nil
+		}
+		return nil, fmt.Errorf("ожидается true или false")
+	}
+
+	if !isText {
+		return nil, fmt.Errorf("ожидается строка")
+	}
+	switch f.Type {
+	case FieldTypeDate:
+		day, err := time.Parse("2006-01-02", text)
+		if err != nil {
+			return nil, fmt.Errorf("ожидается дата ГГГГ-ММ-ДД")
+		}
+		return day.Format("2006-01-02"), nil
+	case FieldTypeEnum:
+		if !containsString(f.Options, text) {
+			return nil, fmt.Errorf("допустимые значения: %s", strings.Join(f.Options, ", "))
+		}
+	default:
+		if len([]rune(text)) > maxFieldStringLength {
+			return nil, fmt.Errorf("не длиннее %d символов", maxFieldStringLength)
+		}
+	}
+	return text, nil
+}
+
+// ValidateCustomFields проверяет значения полей тикета по схеме категории и возвращает
+// приведенные значения. errs содержит ошибку для каждого неверного, лишнего
+// или незаполненного обязательного поля.
+func ValidateCustomFields(fields []CustomField, values map[string]interface{}) (map[string]interface{}, map[string]string) {
+	normalized := make(map[string]interface{})
+	errs := make(map[string]string)
+
+	known := make(map[string]bool, len(fields))
+	for i := range fields {
+		field := &fields[i]
+		known[field.Key] = true
+
+		value, ok := values[field.Key]
+		if text, isText := value.(string); !ok || value == nil || (isText && strings.TrimSpace(text) == "") {
+			if field.Required {
+				errs[field.Key] = "обязательное поле"
+			}
+			continue
+		}
+		result, err := field.Normalize(value)
+		if err != nil {
+			errs[field.Key] = err.Error()
+			continue
+		}
+		normalized[field.Key] = result
+	}
+
+	for key := range values {
+		if !known[key] {
+			errs[key] = "поле не предусмотрено для категории"
+		}
+	}
+
+	if len(normalized) == 0 {
+		normalized = nil
+	}
+	return normalized, errs
+}
+
+// NewCustomFieldRequest — запрос на добавление поля в схему категории
+type NewCustomFieldRequest struct {
+	Category string   `json:"category" binding:"required"`
+	Key      string   `json:"key" binding:"required"`
+	Label    string   `json:"label" binding:"required"`
+	Type     string   `json:"type" binding:"required"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options"`
+}
+
+// UpdateCustomFieldRequest — запрос на изменение поля; категория, ключ и тип не меняются,
+// чтобы сохраненные значения оставались верными
+type UpdateCustomFieldRequest struct {
+	Label    *string  `json:"label"`
+	Required *bool    `json:"required"`
+	Options  []string `json:"options"`
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCustomFieldNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   CustomField
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"строка обрезается", CustomField{Type: FieldTypeString}, "  A-123 ", "A-123", false},
+		{"длинная строка", CustomField{Type: FieldTypeString}, strings.Repeat("я", maxFieldStringLength+1), nil, true},
+		{"строка не числом", CustomField{Type: FieldTypeString}, 42.0, nil, true},
+		{"число", CustomField{Type: FieldTypeNumber}, 12.5, 12.5, false},
+		{"число строкой", CustomField{Type: FieldTypeNumber}, " 12345 ", 12345.0, false},
+		{"не число", CustomField{Type: FieldTypeNumber}, "12a", nil, true},
+		{"NaN", CustomField{Type: FieldTypeNumber}, "NaN", nil, true},
+		{"бесконечность", CustomField{Type: FieldTypeNumber}, "+Inf", nil, true},
+		{"логическое", CustomField{Type: FieldTypeBoolean}, true, true, false},
+		{"логическое строкой", CustomField{Type: FieldTypeBoolean}, "false", false, false},
+		{"не логическое", CustomField{Type: FieldTypeBoolean}, "yes", nil, true},
+		{"дата", CustomField{Type: FieldTypeDate}, "2024-02-29", "2024-02-29", false},
+		{"несуществующая дата", CustomField{Type: FieldTypeDate}, "2023-02-29", nil, true},
+		{"дата с временем", CustomField{Type: FieldTypeDate}, "2024-02-29T10:00:00Z", nil, true},
+		{"вариант enum", CustomField{Type: FieldTypeEnum, Options: []string{"ios", "android"}}, " ios ", "ios", false},
+		{"чужой вариант enum", CustomField{Type: FieldTypeEnum, Options: []string{"ios", "android"}}, "web", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.Normalize(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ожидалась ошибка, получено %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("получено %#v, ожидалось %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCustomFields(t *testing.T) {
+	schema := []CustomField{
+		{Key: "order_number", Type: FieldTypeString, Required: true},
+		{Key: "amount", Type: FieldTypeNumber},
+		{Key: "platform", Type: FieldTypeEnum, Options: []string{"ios", "android"}},
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]interface{}
+		want     map[string]interface{}
+		wantErrs []string
+	}{
+		{
+			name:   "все поля",
+			values: map[string]interface{}{"order_number": "A-1", "amount": "10", "platform": "ios"},
+			want:   map[string]interface{}{"order_number": "A-1", "amount": 10.0, "platform": "ios"},
+		},
+		{
+			name:   "необязательные поля пропущены",
+			values: map[string]interface{}{"order_number": "A-1", "amount": nil},
+			want:   map[string]interface{}{"order_number": "A-1"},
+		},
+		{
+			name:     "обязательное поле пустое",
+			values:   map[string]interface{}{"order_number": "  "},
+			wantErrs: []string{"order_number"},
+		},
+		{
+			name:     "без значений",
+			values:   nil,
+			wantErrs: []string{"order_number"},
+		},
+		{
+			name:     "неверные и лишние поля",
+			values:   map[string]interface{}{"order_number": "A-1", "amount": "много", "color": "red"},
+			wantErrs: []string{"amount", "color"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := ValidateCustomFields(schema, tt.values)
+
+			var gotErrs []string
+			for _, key := range []string{"order_number", "amount", "platform", "color"} {
+				if _, ok := errs[key]; ok {
+					gotErrs = append(gotErrs, key)
+				}
+			}
+			if len(errs) != len(gotErrs) || !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("ошибки %v, ожидались в полях %v", errs, tt.wantErrs)
+			}
+			if len(tt.wantErrs) == 0 && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("получено %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidFieldKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"order_number", true},
+		{"a", true},
+		{"imei2", true},
+		{"", false},
+		{"2fa", false},
+		{"Order", false},
+		{"order-number", false},
+		{"номер", false},
+		{strings.Repeat("a", 51), false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidFieldKey(tt.key); got != tt.want {
+			t.Errorf("ValidFieldKey(%q) = %v, ожидалось %v", tt.key, got, tt.want)
+		}
+	}
+}
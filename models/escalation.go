@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Escalation — сработавшая эскалация тикета. Для тикета, срока и уровня она
+// записывается один раз, поэтому действия не повторяются.
+type Escalation struct {
+	TicketID  int       `json:"ticket_id"`
+	Deadline  string    `json:"deadline"`
+	Level     string    `json:"level"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// Поля тикета, изменения которых записываются в историю
+const (
+	HistoryFieldStatus   = "status"
+	HistoryFieldCategory = "category"
+	HistoryFieldPriority = "priority"
+	HistoryFieldAssignee = "assignee"
+	// Для сообщений, заметок и фотографий в историю пишется ID: new_value при добавлении,
+	// old_value при удалении
+	HistoryFieldMessage = "message"
+	HistoryFieldNote    = "note"
+	HistoryFieldPhoto   = "photo"
+	// Для эскалации SLA в new_value пишется срок и уровень, например "resolution:breached"
+	HistoryFieldEscalation = "escalation"
+	// Для напоминания клиенту о тикете без ответа в new_value пишется число дней ожидания
+	HistoryFieldReminder = "reminder"
+	// Перенос в корзину пишется как new_value "true", восстановление — как old_value "true"
+	HistoryFieldDeleted = "deleted"
+	// При объединении у целевого тикета в new_value пишется ID присоединенного тикета (merged),
+	// у присоединенного — ID целевого (merged_into)
+	HistoryFieldMerged     = "merged"
+	HistoryFieldMergedInto = "merged_into"
+	// Связь пишется со стороны каждого тикета, например "parent_of:42": new_value при создании,
+	// old_value при удалении
+	HistoryFieldLink = "link"
+	// Для оценки клиентом в new_value пишется оценка от 1 до 5
+	HistoryFieldRating = "rating"
+)
+
+// ActorSystem — роль автора изменений, сделанных фоновыми задачами
+const ActorSystem = "system"
+
+// Actor описывает автора изменения тикета
+type Actor struct {
+	Role string
+	// ID отсутствует у системных изменений
+	ID *int64
+}
+
+// SystemActor возвращает автора для изменений фоновых задач
+func SystemActor() Actor {
+	return Actor{Role: ActorSystem}
+}
+
+// TicketHistoryEntry представляет одно изменение тикета
+type TicketHistoryEntry struct {
+	ID        int64     `json:"id"`
+	TicketID  int       `json:"ticket_id"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value"`
+	NewValue  *string   `json:"new_value"`
+	ActorRole string    `json:"actor_role"`
+	ActorID   *int64    `json:"actor_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Подписи значений статуса, приоритета и категории на языке запроса, в базе не хранятся
+	OldLabel string `json:"old_label,omitempty"`
+	NewLabel string `json:"new_label,omitempty"`
+}
+
+// NewHistoryEntry создает запись истории; пустое значение сохраняется как NULL
+func NewHistoryEntry(ticketID int, field, oldValue, newValue string, actor Actor, at time.Time) TicketHistoryEntry {
+	return TicketHistoryEntry{
+		TicketID:  ticketID,
+		Field:     field,
+		OldValue:  historyValue(oldValue),
+		NewValue:  historyValue(newValue),
+		ActorRole: actor.Role,
+		ActorID:   actor.ID,
+		CreatedAt: at,
+	}
+}
+
+// AssigneeValue представляет ответственного в истории тикета; без ответственного — пустая строка
+func AssigneeValue(agentID *int64) string {
+	if agentID == nil {
+		return ""
+	}
+	return strconv.FormatInt(*agentID, 10)
+}
+
+func historyValue(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// Localize заполняет подписи значений статуса, приоритета и категории на языке lang
+func (e *TicketHistoryEntry) Localize(lang string) {
+	var labelOf func(code, lang string) string
+	switch e.Field {
+	case HistoryFieldStatus:
+		labelOf = StatusLabel
+	case HistoryFieldCategory:
+		labelOf = CategoryLabel
+	case HistoryFieldPriority:
+		labelOf = PriorityLabel
+	default:
+		return
+	}
+
+	if e.OldValue != nil {
+		e.OldLabel = labelOf(*e.OldValue, lang)
+	}
+	if e.NewValue != nil {
+		e.NewLabel = labelOf(*e.NewValue, lang)
+	}
+}
@@ -0,0 +1,170 @@
+package models
+
+import "strings"
+
+// Поддерживаемые языки подписей
+const (
+	LangRU = "ru"
+	LangEN = "en"
+)
+
+// DefaultLang используется, если клиент не указал поддерживаемый язык
+const DefaultLang = LangRU
+
+// Languages перечисляет поддерживаемые языки, первый — язык по умолчанию
+var Languages = []string{LangRU, LangEN}
+
+// Категории тикета. Категория по умолчанию назначается тикету, если клиент ее не указал.
+const (
+	CategoryQuestion = "question"
+
+	DefaultCategory = CategoryQuestion
+)
+
+// Categories перечисляет известные категории тикета
+var Categories = []string{
+	CategoryQuestion,
+}
+
+// Приоритеты тикета
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+
+	DefaultPriority = PriorityNormal
+)
+
+// Priorities перечисляет приоритеты тикета по возрастанию
+var Priorities = []string{
+	PriorityLow,
+	PriorityNormal,
+	PriorityHigh,
+	PriorityUrgent,
+}
+
+// NextPriority возвращает приоритет на ступень выше; false, если priority уже наивысший или неизвестен
+func NextPriority(priority string) (string, bool) {
+	for i, p := range Priorities[:len(Priorities)-1] {
+		if p == priority {
+			return Priorities[i+1], true
+		}
+	}
+	return "", false
+}
+
+// rank возвращает позицию значения в списке или -1, если его там нет
+func rank(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// PriorityRank возвращает позицию приоритета в Priorities: чем больше, тем срочнее
+func PriorityRank(priority string) int {
+	return rank(Priorities, priority)
+}
+
+// StatusRank возвращает позицию статуса в Statuses
+func StatusRank(status string) int {
+	return rank(Statuses, status)
+}
+
+// statusLabels — подписи статусов на поддерживаемых языках
+var statusLabels = map[string]map[string]string{
+	StatusOpen:            {LangRU: "открыт", LangEN: "Open"},
+	StatusInProgress:      {LangRU: "в работе", LangEN: "In progress"},
+	StatusWaitingCustomer: {LangRU: "ожидает клиента", LangEN: "Waiting for customer"},
+	StatusResolved:        {LangRU: "решен", LangEN: "Resolved"},
+	StatusClosed:          {LangRU: "закрыт", LangEN: "Closed"},
+	StatusReopened:        {LangRU: "переоткрыт", LangEN: "Reopened"},
+}
+
+// priorityLabels — подписи приоритетов на поддерживаемых языках
+var priorityLabels = map[string]map[string]string{
+	PriorityLow:    {LangRU: "низкий", LangEN: "Low"},
+	PriorityNormal: {LangRU: "обычный", LangEN: "Normal"},
+	PriorityHigh:   {LangRU: "высокий", LangEN: "High"},
+	PriorityUrgent: {LangRU: "срочный", LangEN: "Urgent"},
+}
+
+// categoryLabels — подписи известных категорий на поддерживаемых языках
+var categoryLabels = map[string]map[string]string{
+	CategoryQuestion: {LangRU: "спросить", LangEN: "Question"},
+}
+
+// label возвращает подпись кода на языке lang; при ее отсутствии — на языке
+// по умолчанию, а для неизвестного кода — сам код
+func label(labels map[string]map[string]string, code, lang string) string {
+	byLang, ok := labels[code]
+	if !ok {
+		return code
+	}
+	if text, ok := byLang[lang]; ok {
+		return text
+	}
+	return byLang[DefaultLang]
+}
+
+// lookupCode находит код по самому коду или по его подписи на любом языке
+func lookupCode(labels map[string]map[string]string, value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if _, ok := labels[value]; ok {
+		return value, true
+	}
+	for code, byLang := range labels {
+		for _, text := range byLang {
+			if strings.EqualFold(text, value) {
+				return code, true
+			}
+		}
+	}
+	return "", false
+}
+
+// StatusLabel возвращает подпись статуса на языке lang
+func StatusLabel(status, lang string) string {
+	return label(statusLabels, status, lang)
+}
+
+// CategoryLabel возвращает подпись категории на языке lang.
+// Для категорий вне списка Categories возвращается сама категория.
+func CategoryLabel(category, lang string) string {
+	return label(categoryLabels, category, lang)
+}
+
+// PriorityLabel возвращает подпись приоритета на языке lang
+func PriorityLabel(priority, lang string) string {
+	return label(priorityLabels, priority, lang)
+}
+
+// ParsePriority принимает код приоритета или его подпись и возвращает код
+func ParsePriority(value string) (string, bool) {
+	return lookupCode(priorityLabels, value)
+}
+
+// ParseStatus принимает код статуса или его подпись (для совместимости со
+// старыми клиентами) и возвращает код
+func ParseStatus(value string) (string, bool) {
+	return lookupCode(statusLabels, value)
+}
+
+// NormalizeCategory заменяет подпись известной категории ее кодом,
+// остальные категории возвращает без изменений
+func NormalizeCategory(value string) string {
+	if code, ok := lookupCode(categoryLabels, value); ok {
+		return code
+	}
+	return strings.TrimSpace(value)
+}
+
+// Localize заполняет подписи статуса, приоритета и категории тикета на языке lang
+func (t *Ticket) Localize(lang string) {
+	t.StatusLabel = StatusLabel(t.Status, lang)
+	t.PriorityLabel = PriorityLabel(t.Priority, lang)
+	t.CategoryLabel = CategoryLabel(t.Category, lang)
+}
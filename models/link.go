@@ -0,0 +1,117 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// Типы связей между тикетами. Связь хранится один раз в прямом направлении (relates_to,
+// duplicate_of, parent_of); со стороны второго тикета она видна с обратным типом.
+const (
+	LinkRelatesTo    = "relates_to"
+	LinkDuplicateOf  = "duplicate_of"
+	LinkDuplicatedBy = "duplicated_by"
+	LinkParentOf     = "parent_of"
+	LinkChildOf      = "child_of"
+)
+
+// LinkTypes перечисляет типы связей, которые можно указать при создании
+var LinkTypes = []string{
+	LinkRelatesTo,
+	LinkDuplicateOf,
+	LinkDuplicatedBy,
+	LinkParentOf,
+	LinkChildOf,
+}
+
+// linkInverse — тип связи со стороны второго тикета
+var linkInverse = map[string]string{
+	LinkRelatesTo:    LinkRelatesTo,
+	LinkDuplicateOf:  LinkDuplicatedBy,
+	LinkDuplicatedBy: LinkDuplicateOf,
+	LinkParentOf:     LinkChildOf,
+	LinkChildOf:      LinkParentOf,
+}
+
+// Действия с дочерним тикетом, когда родительский решен или закрыт
+const (
+	// ChildActionNotify — автор дочернего тикета получает уведомление
+	ChildActionNotify = "notify"
+	// ChildActionClose — дочерний тикет закрывается, автор получает уведомление
+	ChildActionClose = "close"
+)
+
+// ChildActions перечисляет действия с дочерним тикетом
+var ChildActions = []string{ChildActionNotify, ChildActionClose}
+
+// ValidLinkType проверяет, что тип связи известен
+func ValidLinkType(linkType string) bool {
+	_, ok := linkInverse[linkType]
+	return ok
+}
+
+// ValidChildAction проверяет, что действие с дочерним тикетом известно
+func ValidChildAction(action string) bool {
+	return action == ChildActionNotify || action == ChildActionClose
+}
+
+// TicketLink — связь тикета TicketID с тикетом LinkedTicketID
+type TicketLink struct {
+	ID             int64  `json:"id"`
+	TicketID       int    `json:"ticket_id"`
+	LinkedTicketID int    `json:"linked_ticket_id"`
+	Type           string `json:"type"`
+	// OnParentResolved задается только для связей родитель — дочерний тикет (ChildAction*)
+	OnParentResolved string    `json:"on_parent_resolved,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Canonical возвращает связь в направлении хранения: child_of и duplicated_by
+// разворачиваются, у relates_to первым идет меньший ID
+func (l TicketLink) Canonical() TicketLink {
+	switch {
+	case l.Type == LinkChildOf || l.Type == LinkDuplicatedBy:
+		return l.reversed()
+	case l.Type == LinkRelatesTo && l.TicketID > l.LinkedTicketID:
+		return l.reversed()
+	}
+	return l
+}
+
+// From возвращает связь со стороны тикета ticketID
+func (l TicketLink) From(ticketID int) TicketLink {
+	if l.TicketID != ticketID {
+		return l.reversed()
+	}
+	return l
+}
+
+func (l TicketLink) reversed() TicketLink {
+	l.TicketID, l.LinkedTicketID = l.LinkedTicketID, l.TicketID
+	l.Type = linkInverse[l.Type]
+	return l
+}
+
+// HistoryValue представляет связь в истории тикета TicketID, например "parent_of:42"
+func (l TicketLink) HistoryValue() string {
+	return l.Type + ":" + strconv.Itoa(l.LinkedTicketID)
+}
+
+// LinkedTicket — связь в карточке тикета: тип со стороны тикета и краткие сведения о втором тикете
+type LinkedTicket struct {
+	LinkID           int64  `json:"link_id"`
+	Type             string `json:"type"`
+	TicketID         int    `json:"ticket_id"`
+	Title            string `json:"title"`
+	Status           string `json:"status"`
+	StatusLabel      string `json:"status_label,omitempty"`
+	OnParentResolved string `json:"on_parent_resolved,omitempty"`
+}
+
+// NewTicketLinkRequest — тело запроса на создание связи
+type NewTicketLinkRequest struct {
+	Type     string `json:"type" binding:"required"`
+	TicketID int    `json:"ticket_id" binding:"required"`
+	// OnParentResolved — действие с дочерним тикетом для parent_of и child_of, по умолчанию notify
+	OnParentResolved string `json:"on_parent_resolved"`
+}
@@ -21,19 +21,65 @@ type Ticket struct {
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
 	Category    string     `json:"category"`
 	CreatedAt   time.Time  `json:"created_at"`
 	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	ReopenedAt  *time.Time `json:"reopened_at,omitempty"`
+	AssigneeID  *int64     `json:"assignee_id"`
+
+	// SLA: время первого ответа поддержки и сроки по политике приоритета и категории
+	FirstResponseAt  *time.Time `json:"first_response_at,omitempty"`
+	FirstResponseDue *time.Time `json:"first_response_due,omitempty"`
+	ResolutionDue    *time.Time `json:"resolution_due,omitempty"`
+	// Моменты, с которых сроки считаются под угрозой; вычисляются вместе со сроками по рабочему календарю
+	FirstResponseRiskAt *time.Time `json:"-"`
+	ResolutionRiskAt    *time.Time `json:"-"`
+
+	// Ожидание клиента: время последнего ответа поддержки, на который клиент еще не ответил,
+	// напоминание и автозакрытие (отметка сохраняется, пока тикет закрыт автоматически)
+	WaitingSince    *time.Time `json:"waiting_since,omitempty"`
+	StaleRemindedAt *time.Time `json:"-"`
+	AutoClosedAt    *time.Time `json:"auto_closed_at,omitempty"`
+
+	// Корзина: время удаления и удаливший администратор. Тикет в корзине скрыт
+	// из всех выборок, кроме списка корзины, пока его не восстановят или не удалят навсегда.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy *int64     `json:"deleted_by,omitempty"`
+
+	// MergedIntoID — тикет, с которым объединен этот (закрытый) тикет-дубликат
+	MergedIntoID *int `json:"merged_into_id,omitempty"`
+
+	// CustomFields — значения дополнительных полей категории (см. CustomField), задаются при создании
+	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+
+	// Вычисляемые поля ответа, в базе не хранятся
+	StatusLabel   string `json:"status_label,omitempty"`
+	PriorityLabel string `json:"priority_label,omitempty"`
+	CategoryLabel string `json:"category_label,omitempty"`
+	SLAStatus     string `json:"sla_status,omitempty"`
 }
 
+// Виды сообщений тикета
+const (
+	// MessageKindReply — сообщение переписки с клиентом
+	MessageKindReply = "reply"
+	// MessageKindNote — внутренняя заметка, видна только поддержке
+	MessageKindNote = "note"
+)
+
 // TicketMessage представляет модель сообщения в тикете
 type TicketMessage struct {
 	ID         int       `json:"id"`
 	TicketID   int       `json:"ticket_id"`
 	SenderType string    `json:"sender_type"` // 'user' или 'support'
 	SenderID   int64     `json:"sender_id"`
+	Kind       string    `json:"kind"` // MessageKindReply или MessageKindNote
 	Message    string    `json:"message"`
 	CreatedAt  time.Time `json:"created_at"`
+	// Mentions — ID сотрудников, упомянутых в заметке
+	Mentions []int64 `json:"mentions,omitempty"`
 }
 
 // TicketPhoto представляет модель фотографии в тикете
@@ -48,23 +94,47 @@ type TicketPhoto struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
-// NewTicketRequest представляет запрос на создание нового тикета
+// NewTicketRequest представляет запрос на создание нового тикета.
+// Для клиента UserID берется из токена, сотрудник поддержки указывает его явно.
+// Приоритет может указать только поддержка.
 type NewTicketRequest struct {
-	UserID      int64  `json:"user_id" binding:"required"`
+	UserID      int64  `json:"user_id"`
 	Title       string `json:"title" binding:"required"`
 	Description string `json:"description" binding:"required"`
 	Category    string `json:"category"`
+	Priority    string `json:"priority"`
+	// CustomFields — значения дополнительных полей категории тикета
+	CustomFields map[string]interface{} `json:"custom_fields"`
 }
 
 // UpdateTicketRequest представляет запрос на обновление тикета
 type UpdateTicketRequest struct {
 	Status   string `json:"status"`
 	Category string `json:"category"`
+	Priority string `json:"priority"`
 }
 
-// NewMessageRequest представляет запрос на создание нового сообщения
+// MergeTicketsRequest перечисляет тикеты-дубликаты, объединяемые с тикетом из пути запроса
+type MergeTicketsRequest struct {
+	SourceIDs []int `json:"source_ids" binding:"required"`
+}
+
+// NewMessageRequest представляет запрос на создание нового сообщения.
+// Отправитель определяется по токену вызывающего.
 type NewMessageRequest struct {
-	SenderType string `json:"sender_type" binding:"required"`
-	SenderID   int64  `json:"sender_id" binding:"required"`
-	Message    string `json:"message" binding:"required"`
+	Message string `json:"message" binding:"required"`
+}
+
+// NewNoteRequest представляет запрос на создание внутренней заметки.
+// Сотрудники упоминаются в тексте как @ID или @логин (часть email до @).
+type NewNoteRequest struct {
+	Message string `json:"message" binding:"required"`
+}
+
+// TokenRequest представляет запрос сервисного клиента на получение токена
+type TokenRequest struct {
+	ClientID     string `json:"client_id" binding:"required"`
+	ClientSecret string `json:"client_secret" binding:"required"`
+	// UserID — пользователь, от имени которого выдается токен клиента (только для can_impersonate)
+	UserID *int64 `json:"user_id"`
 }
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Статусы записей очереди уведомлений
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead"
+)
+
+// OutboxEntry представляет уведомление в очереди на доставку по одному каналу
+type OutboxEntry struct {
+	ID            int64      `json:"id"`
+	Channel       string     `json:"channel"`
+	Event         string     `json:"event"`
+	TicketID      int        `json:"ticket_id"`
+	RecipientID   int64      `json:"recipient_id"`
+	Message       string     `json:"message"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     *string    `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
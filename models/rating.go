@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Границы оценки тикета клиентом
+const (
+	MinRatingScore = 1
+	MaxRatingScore = 5
+	// SatisfiedRatingScore — наименьшая оценка, при которой клиент считается довольным (CSAT)
+	SatisfiedRatingScore = 4
+)
+
+// TicketRating — оценка работы поддержки, которую клиент ставит закрытому тикету один раз.
+// Ответственный и категория запоминаются на момент оценки, чтобы отчет не менялся
+// при последующих изменениях тикета.
+type TicketRating struct {
+	TicketID  int       `json:"ticket_id"`
+	UserID    int64     `json:"user_id"`
+	Score     int       `json:"score"`
+	Comment   string    `json:"comment"`
+	AgentID   *int64    `json:"agent_id"`
+	Category  string    `json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Satisfied сообщает, что клиент доволен: оценка не ниже SatisfiedRatingScore
+func (r *TicketRating) Satisfied() bool {
+	return r.Score >= SatisfiedRatingScore
+}
+
+// NewRatingRequest — тело запроса на оценку тикета
+type NewRatingRequest struct {
+	Score   int    `json:"score" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"max=2000"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// SearchResult — тикет, найденный полнотекстовым поиском
+type SearchResult struct {
+	Ticket Ticket  `json:"ticket"`
+	Rank   float64 `json:"rank"`
+	// MessageID — самое релевантное сообщение тикета, если запрос нашелся в сообщениях
+	MessageID *int `json:"message_id,omitempty"`
+	// TitleHighlight и Snippet — экранированный HTML, найденные слова обрамлены <b></b>
+	TitleHighlight string `json:"title_highlight"`
+	Snippet        string `json:"snippet"`
+}
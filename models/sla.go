@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// Состояния SLA тикета
+const (
+	SLAOk       = "ok"
+	SLAAtRisk   = "at_risk"
+	SLABreached = "breached"
+)
+
+// SLAStatuses перечисляет состояния SLA
+var SLAStatuses = []string{SLAOk, SLAAtRisk, SLABreached}
+
+// ValidSLAStatus проверяет, что состояние SLA известно
+func ValidSLAStatus(status string) bool {
+	for _, s := range SLAStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Сроки SLA, по которым отслеживается и эскалируется тикет
+const (
+	DeadlineFirstResponse = "first_response"
+	DeadlineResolution    = "resolution"
+)
+
+// resolvedTime возвращает время решения тикета: решен или закрыт, что наступило раньше
+func (t *Ticket) resolvedTime() *time.Time {
+	if t.ResolvedAt != nil {
+		return t.ResolvedAt
+	}
+	return t.ClosedAt
+}
+
+// deadlineStatus возвращает состояние одного срока или пустую строку, если срока нет.
+// Срок нарушен, если событие done произошло позже срока или еще не произошло, а срок прошел;
+// под угрозой, если событие не произошло и наступил момент riskAt.
+func deadlineStatus(done, due, riskAt *time.Time, now time.Time) string {
+	if due == nil {
+		return ""
+	}
+
+	at := now
+	if done != nil {
+		at = *done
+	}
+	if at.After(*due) {
+		return SLABreached
+	}
+
+	if done == nil && riskAt != nil && now.After(*riskAt) {
+		return SLAAtRisk
+	}
+	return SLAOk
+}
+
+// DeadlineStatus возвращает состояние срока deadline (DeadlineFirstResponse или DeadlineResolution)
+// на момент now или пустую строку, если срок не задан
+func (t *Ticket) DeadlineStatus(deadline string, now time.Time) string {
+	switch deadline {
+	case DeadlineFirstResponse:
+		return deadlineStatus(t.FirstResponseAt, t.FirstResponseDue, t.FirstResponseRiskAt, now)
+	case DeadlineResolution:
+		return deadlineStatus(t.resolvedTime(), t.ResolutionDue, t.ResolutionRiskAt, now)
+	}
+	return ""
+}
+
+// ComputeSLAStatus возвращает худшее из состояний сроков на момент now или пустую строку, если сроков нет
+func (t *Ticket) ComputeSLAStatus(now time.Time) string {
+	firstResponse := t.DeadlineStatus(DeadlineFirstResponse, now)
+	resolution := t.DeadlineStatus(DeadlineResolution, now)
+
+	switch {
+	case firstResponse == "" && resolution == "":
+		return ""
+	case firstResponse == SLABreached || resolution == SLABreached:
+		return SLABreached
+	case firstResponse == SLAAtRisk || resolution == SLAAtRisk:
+		return SLAAtRisk
+	default:
+		return SLAOk
+	}
+}
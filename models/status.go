@@ -0,0 +1,128 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Статусы тикета — стабильные коды, которые хранятся в базе и возвращаются в API.
+// Подписи для пользователей см. в labels.go.
+const (
+	StatusOpen            = "open"
+	StatusInProgress      = "in_progress"
+	StatusWaitingCustomer = "waiting_customer"
+	StatusResolved        = "resolved"
+	StatusClosed          = "closed"
+	StatusReopened        = "reopened"
+)
+
+// Statuses перечисляет все статусы тикета
+var Statuses = []string{
+	StatusOpen,
+	StatusInProgress,
+	StatusWaitingCustomer,
+	StatusResolved,
+	StatusClosed,
+	StatusReopened,
+}
+
+// statusTransitions — разрешенные переходы между статусами
+var statusTransitions = map[string][]string{
+	StatusOpen:            {StatusInProgress, StatusWaitingCustomer, StatusResolved, StatusClosed},
+	StatusInProgress:      {StatusOpen, StatusWaitingCustomer, StatusResolved, StatusClosed},
+	StatusWaitingCustomer: {StatusInProgress, StatusResolved, StatusClosed},
+	StatusResolved:        {StatusClosed, StatusReopened},
+	StatusClosed:          {StatusReopened},
+	StatusReopened:        {StatusInProgress, StatusWaitingCustomer, StatusResolved, StatusClosed},
+}
+
+// ErrUnknownStatus возвращается для статуса не из списка Statuses
+var ErrUnknownStatus = errors.New("неизвестный статус")
+
+// TransitionError описывает недопустимый переход статуса
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("недопустимый переход статуса: '%s' -> '%s'", e.From, e.To)
+}
+
+// ValidStatus проверяет, что статус известен
+func ValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// AllowedTransitions возвращает статусы, в которые можно перейти из from
+func AllowedTransitions(from string) []string {
+	return append([]string(nil), statusTransitions[from]...)
+}
+
+// CanTransition сообщает, разрешен ли переход из from в to
+func CanTransition(from, to string) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActiveStatus сообщает, ожидает ли тикет работы поддержки (не решен и не закрыт)
+func IsActiveStatus(status string) bool {
+	return status != StatusResolved && status != StatusClosed
+}
+
+// IsFinalStatus сообщает, закрыт ли тикет для новых сообщений
+func IsFinalStatus(status string) bool {
+	return status == StatusClosed
+}
+
+// ApplyStatus переводит тикет в статус to и обновляет связанные отметки времени.
+// Повторная установка текущего статуса ничего не меняет.
+func (t *Ticket) ApplyStatus(to string, now time.Time) error {
+	if !ValidStatus(to) {
+		return fmt.Errorf("%w: '%s'", ErrUnknownStatus, to)
+	}
+	if t.Status == to {
+		return nil
+	}
+	if !CanTransition(t.Status, to) {
+		return &TransitionError{From: t.Status, To: to}
+	}
+
+	switch to {
+	case StatusWaitingCustomer:
+		if t.WaitingSince == nil {
+			t.WaitingSince = &now
+		}
+	case StatusResolved:
+		t.ResolvedAt = &now
+	case StatusClosed:
+		t.ClosedAt = &now
+	case StatusReopened:
+		t.ReopenedAt = &now
+		t.ResolvedAt = nil
+		t.ClosedAt = nil
+		t.AutoClosedAt = nil
+		t.WaitingSince = nil
+		t.StaleRemindedAt = nil
+	}
+
+	t.Status = to
+	return nil
+}
+
+// TrackReply обновляет ожидание клиента после сообщения в тикете: ответ поддержки
+// запускает ожидание заново, сообщение клиента его завершает
+func (t *Ticket) TrackReply(fromSupport bool, at time.Time) {
+	t.StaleRemindedAt = nil
+	if fromSupport {
+		t.WaitingSince = &at
+	} else {
+		t.WaitingSince = nil
+	}
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusOpen, StatusInProgress, true},
+		{StatusOpen, StatusWaitingCustomer, true},
+		{StatusOpen, StatusResolved, true},
+		{StatusOpen, StatusClosed, true},
+		{StatusOpen, StatusReopened, false},
+		{StatusInProgress, StatusOpen, true},
+		{StatusInProgress, StatusReopened, false},
+		{StatusWaitingCustomer, StatusInProgress, true},
+		{StatusWaitingCustomer, StatusOpen, false},
+		{StatusResolved, StatusClosed, true},
+		{StatusResolved, StatusReopened, true},
+		{StatusResolved, StatusInProgress, false},
+		{StatusResolved, StatusOpen, false},
+		{StatusClosed, StatusReopened, true},
+		{StatusClosed, StatusOpen, false},
+		{StatusClosed, StatusResolved, false},
+		{StatusReopened, StatusInProgress, true},
+		{StatusReopened, StatusClosed, true},
+		{StatusReopened, StatusOpen, false},
+		{"unknown", StatusOpen, false},
+		{StatusOpen, "unknown", false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, ожидалось %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestTransitionsKnownStatuses(t *testing.T) {
+	for _, from := range Statuses {
+		if !ValidStatus(from) {
+			t.Errorf("статус %q не описан в матрице переходов", from)
+		}
+		for _, to := range AllowedTransitions(from) {
+			if !ValidStatus(to) {
+				t.Errorf("переход %q -> %q ведет в неизвестный статус", from, to)
+			}
+			if to == from {
+				t.Errorf("переход %q -> %q в тот же статус", from, to)
+			}
+		}
+	}
+}
+
+func TestApplyStatus(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		ticket  Ticket
+		to      string
+		wantErr bool
+		check   func(t *testing.T, ticket Ticket)
+	}{
+		{
+			name:   "решение ставит resolved_at",
+			ticket: Ticket{Status: StatusInProgress},
+			to:     StatusResolved,
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.ResolvedAt == nil || !ticket.ResolvedAt.Equal(now) {
+					t.Errorf("ResolvedAt = %v, ожидалось %v", ticket.ResolvedAt, now)
+				}
+			},
+		},
+		{
+			name:   "закрытие ставит closed_at",
+			ticket: Ticket{Status: StatusResolved, ResolvedAt: &earlier},
+			to:     StatusClosed,
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.ClosedAt == nil || !ticket.ClosedAt.Equal(now) {
+					t.Errorf("ClosedAt = %v, ожидалось %v", ticket.ClosedAt, now)
+				}
+				if ticket.ResolvedAt != &earlier {
+					t.Errorf("ResolvedAt изменен при закрытии")
+				}
+			},
+		},
+		{
+			name:   "переоткрытие сбрасывает решение, закрытие и ожидание",
+			ticket: Ticket{Status: StatusClosed, ResolvedAt: &earlier, ClosedAt: &earlier, AutoClosedAt: &earlier, WaitingSince: &earlier, StaleRemindedAt: &earlier},
+			to:     StatusReopened,
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.ReopenedAt == nil || !ticket.ReopenedAt.Equal(now) {
+					t.Errorf("ReopenedAt = %v, ожидалось %v", ticket.ReopenedAt, now)
+				}
+				if ticket.ResolvedAt != nil || ticket.ClosedAt != nil || ticket.AutoClosedAt != nil ||
+					ticket.WaitingSince != nil || ticket.StaleRemindedAt != nil {
+					t.Errorf("отметки времени не сброшены: %+v", ticket)
+				}
+			},
+		},
+		{
+			name:   "ожидание клиента сохраняет начало ожидания",
+			ticket: Ticket{Status: StatusInProgress, WaitingSince: &earlier},
+			to:     StatusWaitingCustomer,
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.WaitingSince != &earlier {
+					t.Errorf("WaitingSince = %v, ожидалось %v", ticket.WaitingSince, earlier)
+				}
+			},
+		},
+		{
+			name:   "повторная установка статуса ничего не меняет",
+			ticket: Ticket{Status: StatusResolved, ResolvedAt: &earlier},
+			to:     StatusResolved,
+			check: func(t *testing.T, ticket Ticket) {
+				if ticket.ResolvedAt != &earlier {
+					t.Errorf("ResolvedAt изменен")
+				}
+			},
+		},
+		{
+			name:    "недопустимый переход",
+			ticket:  Ticket{Status: StatusClosed},
+			to:      StatusInProgress,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket := tt.ticket
+			err := ticket.ApplyStatus(tt.to, now)
+			if tt.wantErr {
+				var transitionErr *TransitionError
+				if !errors.As(err, &transitionErr) {
+					t.Fatalf("ожидалась TransitionError, получено %v", err)
+				}
+				if ticket.Status != tt.ticket.Status {
+					t.Errorf("статус изменен при ошибке: %q", ticket.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if ticket.Status != tt.to {
+				t.Errorf("Status = %q, ожидалось %q", ticket.Status, tt.to)
+			}
+			tt.check(t, ticket)
+		})
+	}
+}
+
+func TestApplyStatusUnknown(t *testing.T) {
+	ticket := Ticket{Status: StatusOpen}
+	if err := ticket.ApplyStatus("done", time.Now()); !errors.Is(err, ErrUnknownStatus) {
+		t.Errorf("ожидалась ErrUnknownStatus, получено %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"context"
+	"support_front_api/config"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+)
+
+// Значения по умолчанию для параметров доставки
+const (
+	defaultPollInterval = 5 * time.Second
+	defaultBatchSize    = 20
+	defaultMaxAttempts  = 8
+	defaultBaseDelay    = 10 * time.Second
+	defaultMaxDelay     = time.Hour
+	sendTimeout         = 15 * time.Second
+)
+
+// Dispatcher доставляет уведомления из очереди с экспоненциальной задержкой
+// между попытками и переводит запись в dead после исчерпания попыток
+type Dispatcher struct {
+	store    repository.Store
+	channels map[string]Notifier
+
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+}
+
+// NewDispatcher создает диспетчер для перечисленных каналов
+func NewDispatcher(store repository.Store, channels []Channel, cfg config.OutboxConfig) *Dispatcher {
+	d := &Dispatcher{
+		store:        store,
+		channels:     make(map[string]Notifier, len(channels)),
+		pollInterval: secondsOr(cfg.PollIntervalSeconds, defaultPollInterval),
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+		baseDelay:    secondsOr(cfg.BaseDelaySeconds, defaultBaseDelay),
+		maxDelay:     secondsOr(cfg.MaxDelaySeconds, defaultMaxDelay),
+	}
+	if d.batchSize <= 0 {
+		d.batchSize = defaultBatchSize
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = defaultMaxAttempts
+	}
+	for _, channel := range channels {
+		d.channels[channel.Name] = channel.Notifier
+	}
+	return d
+}
+
+func secondsOr(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Run разбирает очередь до отмены ctx
+func (d *Dispatcher) Run(ctx context.Context) {
+	logger.LogInfo("Запущен диспетчер уведомлений, каналов: %d", len(d.channels))
+
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Разбираем очередь, пока в ней есть готовые записи
+		for {
+			processed, err := d.DispatchOnce(ctx)
+			if err != nil {
+				logger.LogError("Ошибка при разборе очереди уведомлений: %v", err)
+				break
+			}
+			if processed < d.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.LogInfo("Диспетчер уведомлений остановлен")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce обрабатывает одну пачку готовых записей и возвращает их количество
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	// Аренда с запасом покрывает отправку всей пачки: если процесс упадет,
+	// записи снова станут доступны после ее окончания
+	lease := sendTimeout*time.Duration(d.batchSize) + d.pollInterval
+
+	entries, err := d.store.Outbox().ClaimDue(ctx, time.Now(), d.batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+		d.deliver(ctx, entry)
+	}
+
+	return len(entries), nil
+}
+
+// deliver отправляет одну запись и сохраняет результат попытки
+func (d *Dispatcher) deliver(ctx context.Context, entry models.OutboxEntry) {
+	attempts := entry.Attempts + 1
+
+	notifier, ok := d.channels[entry.Channel]
+	if !ok {
+		d.markDead(ctx, entry, attempts, "канал "+entry.Channel+" не настроен")
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	err := notifier.Notify(sendCtx, Notification{
+		Event:       entry.Event,
+		TicketID:    entry.TicketID,
+		RecipientID: entry.RecipientID,
+		Message:     entry.Message,
+	})
+	cancel()
+
+	if err == nil {
+		if err := d.store.Outbox().MarkSent(ctx, entry.ID, time.Now()); err != nil {
+			logger.LogError("Ошибка при отметке уведомления %d как отправленного: %v", entry.ID, err)
+		}
+		return
+	}
+
+	if attempts >= d.maxAttempts {
+		d.markDead(ctx, entry, attempts, err.Error())
+		return
+	}
+
+	next := time.Now().Add(d.backoff(attempts))
+	logger.LogWarning("Ошибка при отправке уведомления %d (попытка %d из %d), повтор в %s: %v",
+		entry.ID, attempts, d.maxAttempts, next.Format(time.RFC3339), err)
+	if err := d.store.Outbox().MarkFailed(ctx, entry.ID, attempts, next, err.Error()); err != nil {
+		logger.LogError("Ошибка при сохранении попытки уведомления %d: %v", entry.ID, err)
+	}
+}
+
+func (d *Dispatcher) markDead(ctx context.Context, entry models.OutboxEntry, attempts int, reason string) {
+	logger.LogError("Уведомление %d по каналу %s не доставлено после %d попыток: %s",
+		entry.ID, entry.Channel, attempts, reason)
+	if err := d.store.Outbox().MarkDead(ctx, entry.ID, attempts, reason); err != nil {
+		logger.LogError("Ошибка при переводе уведомления %d в dead: %v", entry.ID, err)
+	}
+}
+
+// backoff возвращает задержку перед следующей попыткой: base * 2^(attempts-1), но не больше max
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.baseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.maxDelay {
+			return d.maxDelay
+		}
+	}
+	return delay
+}
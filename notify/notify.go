@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"support_front_api/models"
+	"sync"
+)
+
+// События, о которых отправляются уведомления
+const (
+	EventMessageAdded  = "message_added"
+	EventTicketUpdated = "ticket_updated"
+	EventSLAEscalated  = "sla_escalated"
+	EventStaleReminder = "stale_reminder"
+	// EventNoteMentioned — сотрудника упомянули во внутренней заметке
+	EventNoteMentioned = "note_mentioned"
+)
+
+// internalEvents — события для сотрудников с внутренними данными тикета. Их получают
+// только каналы, явно подписанные на них (см. Superconnect.Handles).
+var internalEvents = map[string]bool{
+	EventNoteMentioned: true,
+}
+
+// IsInternalEvent сообщает, что событие предназначено только сотрудникам
+func IsInternalEvent(event string) bool {
+	return internalEvents[event]
+}
+
+// Notification описывает уведомление о событии тикета
+type Notification struct {
+	Event       string `json:"event"`
+	TicketID    int    `json:"ticket_id"`
+	RecipientID int64  `json:"recipient_id"`
+	Message     string `json:"message"`
+}
+
+// StatusNotification возвращает текст уведомления клиенту о смене статуса тикета;
+// reason, если задан, поясняет причину смены. При закрытии клиента просят оценить
+// работу поддержки.
+func StatusNotification(ticketID int, status, reason string) string {
+	message := fmt.Sprintf("Статус вашего тикета %d изменен на '%s'", ticketID, models.StatusLabel(status, models.LangRU))
+	if reason != "" {
+		message += ": " + reason
+	}
+	if status == models.StatusClosed {
+		message += fmt.Sprintf(". Оцените, пожалуйста, нашу работу от %d до %d и оставьте комментарий",
+			models.MinRatingScore, models.MaxRatingScore)
+	}
+	return message
+}
+
+// Notifier доставляет уведомления по одному каналу
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// EventFilter реализуют каналы, подписанные только на часть событий
+type EventFilter interface {
+	Handles(event string) bool
+}
+
+// Channel — именованный канал доставки; имя сохраняется в очереди уведомлений
+type Channel struct {
+	Name     string
+	Notifier Notifier
+}
+
+// handles сообщает, нужно ли доставлять событие по каналу
+func (c Channel) handles(event string) bool {
+	if filter, ok := c.Notifier.(EventFilter); ok {
+		return filter.Handles(event)
+	}
+	return true
+}
+
+// Multi рассылает уведомление по всем каналам и объединяет их ошибки
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Noop ничего не отправляет
+type Noop struct{}
+
+func (Noop) Notify(ctx context.Context, n Notification) error { return nil }
+
+// Recorder запоминает уведомления вместо отправки; используется в тестах
+type Recorder struct {
+	mu            sync.Mutex
+	notifications []Notification
+}
+
+func (r *Recorder) Notify(ctx context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+// Notifications возвращает копию записанных уведомлений
+func (r *Recorder) Notifications() []Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Notification(nil), r.notifications...)
+}
+
+// Reset очищает записанные уведомления
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifications = nil
+}
@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+)
+
+// Outbox ставит уведомления в очередь для каждого подписанного канала.
+// Запись делается через переданное хранилище, поэтому внутри InTx
+// уведомление сохраняется в одной транзакции с изменением тикета.
+type Outbox struct {
+	channels []Channel
+}
+
+// NewOutbox создает очередь для перечисленных каналов
+func NewOutbox(channels []Channel) *Outbox {
+	return &Outbox{channels: channels}
+}
+
+// Enqueue добавляет в очередь по записи на каждый канал, подписанный на событие
+func (o *Outbox) Enqueue(ctx context.Context, store repository.Store, n Notification) error {
+	now := time.Now()
+
+	var entries []models.OutboxEntry
+	for _, channel := range o.channels {
+		if !channel.handles(n.Event) {
+			continue
+		}
+		entries = append(entries, models.OutboxEntry{
+			Channel:       channel.Name,
+			Event:         n.Event,
+			TicketID:      n.TicketID,
+			RecipientID:   n.RecipientID,
+			Message:       n.Message,
+			Status:        models.OutboxPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	return store.Outbox().Enqueue(ctx, entries)
+}
@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"support_front_api/config"
+	"time"
+)
+
+// Superconnect отправляет уведомления через шлюз superconnect
+type Superconnect struct {
+	cfg    config.SuperconnectConfig
+	client *http.Client
+}
+
+// NewSuperconnect создает канал superconnect по конфигурации
+func NewSuperconnect(cfg config.SuperconnectConfig) *Superconnect {
+	return &Superconnect{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify отправляет уведомление, если канал подписан на его событие.
+// Если в конфигурации канала задан recipient_id, уведомление уходит ему.
+func (s *Superconnect) Notify(ctx context.Context, n Notification) error {
+	if !s.Handles(n.Event) {
+		return nil
+	}
+
+	recipientID := n.RecipientID
+	if s.cfg.RecipientID != 0 {
+		recipientID = s.cfg.RecipientID
+	}
+
+	data := url.Values{}
+	data.Set("super_connect_token", s.cfg.Token)
+	data.Set("sender_id", s.cfg.SenderID)
+	data.Set("message", n.Message)
+	data.Set("accepter_id", strconv.FormatInt(recipientID, 10))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("superconnect %s: %v", s.cfg.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("superconnect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("superconnect %s: код ответа %d", s.cfg.URL, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Handles сообщает, подписан ли канал на событие. Пустой список — все события,
+// кроме внутренних: их канал получает, только если они перечислены явно.
+func (s *Superconnect) Handles(event string) bool {
+	if len(s.cfg.Events) == 0 {
+		return !IsInternalEvent(event)
+	}
+	for _, e := range s.cfg.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ChannelsFromConfig собирает все включенные в конфигурации каналы.
+// Каналы без имени получают имя superconnect_N по порядку.
+func ChannelsFromConfig(cfg config.NotificationsConfig) []Channel {
+	var channels []Channel
+	for i, sc := range cfg.Superconnect {
+		name := sc.Name
+		if name == "" {
+			name = fmt.Sprintf("superconnect_%d", i+1)
+		}
+		channels = append(channels, Channel{Name: name, Notifier: NewSuperconnect(sc)})
+	}
+	return channels
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+type agentRepo struct {
+	s *Store
+}
+
+func (r *agentRepo) List(ctx context.Context, page repository.Page) ([]models.Agent, error) {
+	defer r.s.lock()()
+
+	agents := make([]models.Agent, 0, len(r.s.d.agents))
+	for _, agent := range r.s.d.agents {
+		agents = append(agents, agent)
+	}
+
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].ID < agents[j].ID
+	})
+
+	return paginatePage(agents, page, repository.KeyNone, false, func(agent models.Agent) repository.Cursor {
+		return repository.Cursor{ID: agent.ID}
+	}), nil
+}
+
+func (r *agentRepo) ListAvailable(ctx context.Context) ([]models.Agent, error) {
+	defer r.s.lock()()
+
+	var agents []models.Agent
+	for _, agent := range r.s.d.agents {
+		if agent.Available() {
+			agents = append(agents, agent)
+		}
+	}
+
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].ID < agents[j].ID
+	})
+
+	return agents, nil
+}
+
+func (r *agentRepo) Count(ctx context.Context) (int, error) {
+	defer r.s.lock()()
+
+	return len(r.s.d.agents), nil
+}
+
+func (r *agentRepo) GetByID(ctx context.Context, id int64) (*models.Agent, error) {
+	defer r.s.lock()()
+
+	agent, ok := r.s.d.agents[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &agent, nil
+}
+
+func (r *agentRepo) Exists(ctx context.Context, id int64) (bool, error) {
+	defer r.s.lock()()
+
+	_, ok := r.s.d.agents[id]
+	return ok, nil
+}
+
+func (r *agentRepo) Create(ctx context.Context, agent *models.Agent) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.agents[agent.ID]; ok {
+		return fmt.Errorf("сотрудник %d уже существует", agent.ID)
+	}
+	r.s.d.agents[agent.ID] = *agent
+	return nil
+}
+
+func (r *agentRepo) Update(ctx context.Context, agent *models.Agent) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.agents[agent.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.d.agents[agent.ID] = *agent
+	return nil
+}
+
+// Delete удаляет сотрудника и, как ON DELETE SET NULL в базе, снимает его с тикетов
+func (r *agentRepo) Delete(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.agents[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.d.agents, id)
+
+	for ticketID, ticket := range r.s.d.tickets {
+		if ticket.AssigneeID != nil && *ticket.AssigneeID == id {
+			ticket.AssigneeID = nil
+			r.s.d.tickets[ticketID] = ticket
+		}
+	}
+	return nil
+}
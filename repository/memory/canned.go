@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+type cannedRepo struct {
+	s *Store
+}
+
+// matchCanned проверяет шаблон по условиям фильтра так же, как cannedWhere в postgres
+func matchCanned(response models.CannedResponse, filter repository.CannedResponseFilter) bool {
+	own := response.OwnerID != nil && *response.OwnerID == filter.VisibleTo
+	switch filter.Scope {
+	case repository.CannedScopeShared:
+		if !response.Shared() {
+			return false
+		}
+	case repository.CannedScopePersonal:
+		if !own {
+			return false
+		}
+	default:
+		if !response.Shared() && !own {
+			return false
+		}
+	}
+	return filter.Category == "" || response.Category == "" || response.Category == filter.Category
+}
+
+func (r *cannedRepo) filter(filter repository.CannedResponseFilter) []models.CannedResponse {
+	var responses []models.CannedResponse
+	for _, response := range r.s.d.canned {
+		if matchCanned(response, filter) {
+			responses = append(responses, response)
+		}
+	}
+	return responses
+}
+
+func (r *cannedRepo) List(ctx context.Context, filter repository.CannedResponseFilter) ([]models.CannedResponse, error) {
+	defer r.s.lock()()
+
+	responses := r.filter(filter)
+	key := func(response models.CannedResponse) repository.Cursor {
+		return repository.Cursor{Key: response.Category, ID: response.ID}
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return key(responses[i]).Compare(repository.KeyText, key(responses[j])) < 0
+	})
+
+	return paginatePage(responses, filter.Page, repository.KeyText, false, key), nil
+}
+
+func (r *cannedRepo) Count(ctx context.Context, filter repository.CannedResponseFilter) (int, error) {
+	defer r.s.lock()()
+
+	return len(r.filter(filter)), nil
+}
+
+func (r *cannedRepo) GetByID(ctx context.Context, id int64) (*models.CannedResponse, error) {
+	defer r.s.lock()()
+
+	response, ok := r.s.d.canned[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &response, nil
+}
+
+func (r *cannedRepo) Create(ctx context.Context, response *models.CannedResponse) error {
+	defer r.s.lock()()
+
+	r.s.d.cannedSeq++
+	response.ID = r.s.d.cannedSeq
+	r.s.d.canned[response.ID] = *response
+	return nil
+}
+
+func (r *cannedRepo) Update(ctx context.Context, response *models.CannedResponse) error {
+	defer r.s.lock()()
+
+	stored, ok := r.s.d.canned[response.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.Title = response.Title
+	stored.Body = response.Body
+	stored.Category = response.Category
+	stored.UpdatedAt = response.UpdatedAt
+	r.s.d.canned[response.ID] = stored
+	return nil
+}
+
+func (r *cannedRepo) Delete(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.canned[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.d.canned, id)
+	return nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+type customFieldRepo struct {
+	s *Store
+}
+
+func (r *customFieldRepo) List(ctx context.Context, category string) ([]models.CustomField, error) {
+	defer r.s.lock()()
+
+	var fields []models.CustomField
+	for _, field := range r.s.d.customFields {
+		if category == "" || field.Category == category {
+			fields = append(fields, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Category != fields[j].Category {
+			return fields[i].Category < fields[j].Category
+		}
+		return fields[i].ID < fields[j].ID
+	})
+	return fields, nil
+}
+
+func (r *customFieldRepo) GetByID(ctx context.Context, id int64) (*models.CustomField, error) {
+	defer r.s.lock()()
+
+	field, ok := r.s.d.customFields[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &field, nil
+}
+
+func (r *customFieldRepo) Create(ctx context.Context, field *models.CustomField) (bool, error) {
+	defer r.s.lock()()
+
+	// То же ограничение, что у уникального индекса (category, key)
+	for _, f := range r.s.d.customFields {
+		if f.Category == field.Category && f.Key == field.Key {
+			return false, nil
+		}
+	}
+
+	r.s.d.customFieldSeq++
+	field.ID = r.s.d.customFieldSeq
+	r.s.d.customFields[field.ID] = *field
+	return true, nil
+}
+
+func (r *customFieldRepo) Update(ctx context.Context, field *models.CustomField) error {
+	defer r.s.lock()()
+
+	stored, ok := r.s.d.customFields[field.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.Label = field.Label
+	stored.Required = field.Required
+	stored.Options = field.Options
+	r.s.d.customFields[field.ID] = stored
+	return nil
+}
+
+func (r *customFieldRepo) Delete(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.customFields[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.d.customFields, id)
+	return nil
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"support_front_api/models"
+	"time"
+)
+
+type escalationRepo struct {
+	s *Store
+}
+
+func (r *escalationRepo) Record(ctx context.Context, escalation models.Escalation) (bool, error) {
+	defer r.s.lock()()
+
+	// Время не входит в ключ, как и в первичный ключ таблицы
+	key := escalation
+	key.CreatedAt = time.Time{}
+	if _, ok := r.s.d.escalations[key]; ok {
+		return false, nil
+	}
+	r.s.d.escalations[key] = struct{}{}
+	return true, nil
+}
+
+func (r *escalationRepo) Exists(ctx context.Context, escalation models.Escalation) (bool, error) {
+	defer r.s.lock()()
+
+	key := escalation
+	key.CreatedAt = time.Time{}
+	_, ok := r.s.d.escalations[key]
+	return ok, nil
+}
+
+func (r *escalationRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	defer r.s.lock()()
+
+	for key := range r.s.d.escalations {
+		if key.TicketID == ticketID {
+			delete(r.s.d.escalations, key)
+		}
+	}
+	return nil
+}
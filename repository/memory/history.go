@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+type historyRepo struct {
+	s *Store
+}
+
+func (r *historyRepo) Add(ctx context.Context, entries ...models.TicketHistoryEntry) error {
+	defer r.s.lock()()
+
+	for i := range entries {
+		r.s.d.historySeq++
+		entries[i].ID = r.s.d.historySeq
+		r.s.d.history[entries[i].ID] = entries[i]
+	}
+	return nil
+}
+
+func (r *historyRepo) ListByTicket(ctx context.Context, ticketID int, page repository.Page) ([]models.TicketHistoryEntry, error) {
+	defer r.s.lock()()
+
+	var entries []models.TicketHistoryEntry
+	for _, entry := range r.s.d.history {
+		if entry.TicketID == ticketID {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	return paginatePage(entries, page, repository.KeyTime, false, func(entry models.TicketHistoryEntry) repository.Cursor {
+		return repository.Cursor{Key: repository.TimeKey(&entry.CreatedAt), ID: entry.ID}
+	}), nil
+}
+
+func (r *historyRepo) CountByTicket(ctx context.Context, ticketID int) (int, error) {
+	defer r.s.lock()()
+
+	count := 0
+	for _, entry := range r.s.d.history {
+		if entry.TicketID == ticketID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *historyRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	defer r.s.lock()()
+
+	for id, entry := range r.s.d.history {
+		if entry.TicketID == ticketID {
+			delete(r.s.d.history, id)
+		}
+	}
+	return nil
+}
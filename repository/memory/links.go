@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+type linkRepo struct {
+	s *Store
+}
+
+func (r *linkRepo) ListByTicket(ctx context.Context, ticketID int) ([]models.TicketLink, error) {
+	defer r.s.lock()()
+
+	var links []models.TicketLink
+	for _, l := range r.s.d.links {
+		if l.TicketID == ticketID || l.LinkedTicketID == ticketID {
+			links = append(links, l)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].ID < links[j].ID })
+	return links, nil
+}
+
+func (r *linkRepo) GetByID(ctx context.Context, id int64) (*models.TicketLink, error) {
+	defer r.s.lock()()
+
+	l, ok := r.s.d.links[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &l, nil
+}
+
+func (r *linkRepo) Create(ctx context.Context, link *models.TicketLink) (bool, error) {
+	defer r.s.lock()()
+
+	// Те же ограничения, что у уникальных индексов таблицы
+	for _, l := range r.s.d.links {
+		samePair := (l.TicketID == link.TicketID && l.LinkedTicketID == link.LinkedTicketID) ||
+			(l.TicketID == link.LinkedTicketID && l.LinkedTicketID == link.TicketID)
+		secondParent := l.Type == models.LinkParentOf && link.Type == models.LinkParentOf &&
+			l.LinkedTicketID == link.LinkedTicketID
+		if samePair || secondParent {
+			return false, nil
+		}
+	}
+
+	r.s.d.linkSeq++
+	link.ID = r.s.d.linkSeq
+	r.s.d.links[link.ID] = *link
+	return true, nil
+}
+
+func (r *linkRepo) Delete(ctx context.Context, id int64) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.links[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.d.links, id)
+	return nil
+}
+
+func (r *linkRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	defer r.s.lock()()
+
+	for id, l := range r.s.d.links {
+		if l.TicketID == ticketID || l.LinkedTicketID == ticketID {
+			delete(r.s.d.links, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package memory
+
+import "context"
+
+type lockRepo struct {
+	s *Store
+}
+
+// TryLock всегда успешна: хранилище в памяти работает в одном процессе,
+// а транзакция InTx уже держит общую блокировку
+func (r *lockRepo) TryLock(ctx context.Context, key int64) (bool, error) {
+	return true, nil
+}
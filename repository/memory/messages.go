@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+type messageRepo struct {
+	s *Store
+}
+
+// matchMessage проверяет сообщение по условиям фильтра
+func matchMessage(message models.TicketMessage, ticketID int, filter repository.MessageFilter) bool {
+	return message.TicketID == ticketID && !(filter.WithoutNotes && message.Kind == models.MessageKindNote)
+}
+
+func (r *messageRepo) ListByTicket(ctx context.Context, ticketID int, filter repository.MessageFilter) ([]models.TicketMessage, error) {
+	defer r.s.lock()()
+
+	var messages []models.TicketMessage
+	for _, message := range r.s.d.messages {
+		if matchMessage(message, ticketID, filter) {
+			messages = append(messages, message)
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].CreatedAt.Equal(messages[j].CreatedAt) {
+			return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+
+	return paginatePage(messages, filter.Page, repository.KeyTime, false, func(message models.TicketMessage) repository.Cursor {
+		return repository.Cursor{Key: repository.TimeKey(&message.CreatedAt), ID: int64(message.ID)}
+	}), nil
+}
+
+func (r *messageRepo) CountByTicket(ctx context.Context, ticketID int, filter repository.MessageFilter) (int, error) {
+	defer r.s.lock()()
+
+	count := 0
+	for _, message := range r.s.d.messages {
+		if matchMessage(message, ticketID, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *messageRepo) Create(ctx context.Context, message *models.TicketMessage) error {
+	defer r.s.lock()()
+
+	r.s.d.messageSeq++
+	message.ID = r.s.d.messageSeq
+	r.s.d.messages[message.ID] = *message
+	return nil
+}
+
+func (r *messageRepo) MoveToTicket(ctx context.Context, fromID, toID int) (int, error) {
+	defer r.s.lock()()
+
+	moved := 0
+	for id, message := range r.s.d.messages {
+		if message.TicketID == fromID {
+			message.TicketID = toID
+			r.s.d.messages[id] = message
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+func (r *messageRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	defer r.s.lock()()
+
+	for id, message := range r.s.d.messages {
+		if message.TicketID == ticketID {
+			delete(r.s.d.messages, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+)
+
+type outboxRepo struct {
+	s *Store
+}
+
+func (r *outboxRepo) Enqueue(ctx context.Context, entries []models.OutboxEntry) error {
+	defer r.s.lock()()
+
+	for i := range entries {
+		r.s.d.outboxSeq++
+		entries[i].ID = r.s.d.outboxSeq
+		r.s.d.outbox[entries[i].ID] = entries[i]
+	}
+	return nil
+}
+
+func (r *outboxRepo) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.OutboxEntry, error) {
+	defer r.s.lock()()
+
+	var due []models.OutboxEntry
+	for _, entry := range r.s.d.outbox {
+		if entry.Status == models.OutboxPending && !entry.NextAttemptAt.After(now) {
+			due = append(due, entry)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	due = paginate(due, limit, 0)
+
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		r.s.d.outbox[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+// updateOutbox применяет fn к записи, если она существует
+func (r *outboxRepo) updateOutbox(id int64, fn func(entry *models.OutboxEntry) bool) error {
+	defer r.s.lock()()
+
+	entry, ok := r.s.d.outbox[id]
+	if !ok || !fn(&entry) {
+		return repository.ErrNotFound
+	}
+	r.s.d.outbox[id] = entry
+	return nil
+}
+
+func (r *outboxRepo) MarkSent(ctx context.Context, id int64, at time.Time) error {
+	return r.updateOutbox(id, func(entry *models.OutboxEntry) bool {
+		entry.Status = models.OutboxSent
+		entry.Attempts++
+		entry.SentAt = &at
+		entry.LastError = nil
+		return true
+	})
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return r.updateOutbox(id, func(entry *models.OutboxEntry) bool {
+		entry.Attempts = attempts
+		entry.NextAttemptAt = nextAttemptAt
+		entry.LastError = &lastError
+		return true
+	})
+}
+
+func (r *outboxRepo) MarkDead(ctx context.Context, id int64, attempts int, lastError string) error {
+	return r.updateOutbox(id, func(entry *models.OutboxEntry) bool {
+		entry.Status = models.OutboxDead
+		entry.Attempts = attempts
+		entry.LastError = &lastError
+		return true
+	})
+}
+
+func (r *outboxRepo) List(ctx context.Context, status string, page repository.Page) ([]models.OutboxEntry, error) {
+	defer r.s.lock()()
+
+	var entries []models.OutboxEntry
+	for _, entry := range r.s.d.outbox {
+		if status == "" || entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+
+	return paginatePage(entries, page, repository.KeyNone, true, func(entry models.OutboxEntry) repository.Cursor {
+		return repository.Cursor{ID: entry.ID}
+	}), nil
+}
+
+func (r *outboxRepo) GetByID(ctx context.Context, id int64) (*models.OutboxEntry, error) {
+	defer r.s.lock()()
+
+	entry, ok := r.s.d.outbox[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &entry, nil
+}
+
+func (r *outboxRepo) Replay(ctx context.Context, id int64, now time.Time) error {
+	return r.updateOutbox(id, func(entry *models.OutboxEntry) bool {
+		if entry.Status != models.OutboxDead {
+			return false
+		}
+		entry.Status = models.OutboxPending
+		entry.Attempts = 0
+		entry.NextAttemptAt = now
+		return true
+	})
+}
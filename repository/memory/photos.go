@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+type photoRepo struct {
+	s *Store
+}
+
+func (r *photoRepo) ListByTicket(ctx context.Context, ticketID int) ([]models.TicketPhoto, error) {
+	defer r.s.lock()()
+
+	var photos []models.TicketPhoto
+	for _, photo := range r.s.d.photos {
+		if photo.TicketID == ticketID {
+			photos = append(photos, photo)
+		}
+	}
+
+	sort.Slice(photos, func(i, j int) bool {
+		if !photos[i].CreatedAt.Equal(photos[j].CreatedAt) {
+			return photos[i].CreatedAt.Before(photos[j].CreatedAt)
+		}
+		return photos[i].ID < photos[j].ID
+	})
+
+	return photos, nil
+}
+
+func (r *photoRepo) GetByID(ctx context.Context, id int) (*models.TicketPhoto, error) {
+	defer r.s.lock()()
+
+	photo, ok := r.s.d.photos[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &photo, nil
+}
+
+func (r *photoRepo) Create(ctx context.Context, photo *models.TicketPhoto) error {
+	defer r.s.lock()()
+
+	r.s.d.photoSeq++
+	photo.ID = r.s.d.photoSeq
+	r.s.d.photos[photo.ID] = *photo
+	return nil
+}
+
+func (r *photoRepo) Delete(ctx context.Context, id int) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.photos[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.d.photos, id)
+	return nil
+}
+
+func (r *photoRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	defer r.s.lock()()
+
+	for id, photo := range r.s.d.photos {
+		if photo.TicketID == ticketID {
+			delete(r.s.d.photos, id)
+		}
+	}
+	return nil
+}
+
+func (r *photoRepo) MoveToTicket(ctx context.Context, fromID, toID int) (int, error) {
+	defer r.s.lock()()
+
+	moved := 0
+	for id, photo := range r.s.d.photos {
+		if photo.TicketID == fromID {
+			photo.TicketID = toID
+			r.s.d.photos[id] = photo
+			moved++
+		}
+	}
+	return moved, nil
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+type ratingRepo struct {
+	s *Store
+}
+
+func (r *ratingRepo) GetByTicket(ctx context.Context, ticketID int) (*models.TicketRating, error) {
+	defer r.s.lock()()
+
+	rating, ok := r.s.d.ratings[ticketID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &rating, nil
+}
+
+func (r *ratingRepo) Create(ctx context.Context, rating *models.TicketRating) (bool, error) {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.ratings[rating.TicketID]; ok {
+		return false, nil
+	}
+	r.s.d.ratings[rating.TicketID] = *rating
+	return true, nil
+}
+
+func (r *ratingRepo) List(ctx context.Context, filter repository.RatingFilter) ([]models.TicketRating, error) {
+	defer r.s.lock()()
+
+	var ratings []models.TicketRating
+	for _, rating := range r.s.d.ratings {
+		if filter.From != nil && rating.CreatedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !rating.CreatedAt.Before(*filter.To) {
+			continue
+		}
+		ratings = append(ratings, rating)
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		if !ratings[i].CreatedAt.Equal(ratings[j].CreatedAt) {
+			return ratings[i].CreatedAt.Before(ratings[j].CreatedAt)
+		}
+		return ratings[i].TicketID < ratings[j].TicketID
+	})
+	return ratings, nil
+}
+
+func (r *ratingRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	defer r.s.lock()()
+
+	delete(r.s.d.ratings, ticketID)
+	return nil
+}
@@ -0,0 +1,21 @@
+package memory
+
+import "context"
+
+type routingRepo struct {
+	s *Store
+}
+
+// LockCursor не требует отдельной блокировки: транзакция InTx держит общую
+func (r *routingRepo) LockCursor(ctx context.Context, key string) (int64, error) {
+	defer r.s.lock()()
+
+	return r.s.d.cursors[key], nil
+}
+
+func (r *routingRepo) SetCursor(ctx context.Context, key string, agentID int64) error {
+	defer r.s.lock()()
+
+	r.s.d.cursors[key] = agentID
+	return nil
+}
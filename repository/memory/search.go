@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+// searchRepo упрощенно повторяет полнотекстовый поиск: ищет подстроки слов запроса
+// без учета регистра и морфологии, ранг — количество совпадений
+type searchRepo struct {
+	s *Store
+}
+
+// searchTerms разбирает запрос websearch на слова, пропуская исключения и оператор or
+func searchTerms(text string) []*regexp.Regexp {
+	var terms []*regexp.Regexp
+	for _, word := range strings.Fields(strings.ReplaceAll(text, `"`, " ")) {
+		if strings.HasPrefix(word, "-") || strings.EqualFold(word, "or") {
+			continue
+		}
+		terms = append(terms, regexp.MustCompile("(?i)"+regexp.QuoteMeta(word)))
+	}
+	return terms
+}
+
+// countMatches возвращает число совпадений, если в тексте есть все слова, иначе 0
+func countMatches(text string, terms []*regexp.Regexp) int {
+	total := 0
+	for _, term := range terms {
+		n := len(term.FindAllStringIndex(text, -1))
+		if n == 0 {
+			return 0
+		}
+		total += n
+	}
+	return total
+}
+
+// highlightTerms экранирует HTML и обрамляет совпадения тегами <b>
+func highlightTerms(text string, terms []*regexp.Regexp) string {
+	marked := make([]bool, len(text))
+	for _, term := range terms {
+		for _, loc := range term.FindAllStringIndex(text, -1) {
+			for i := loc[0]; i < loc[1]; i++ {
+				marked[i] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	for start := 0; start < len(text); {
+		end := start
+		for end < len(text) && marked[end] == marked[start] {
+			end++
+		}
+		if marked[start] {
+			b.WriteString("<b>" + html.EscapeString(text[start:end]) + "</b>")
+		} else {
+			b.WriteString(html.EscapeString(text[start:end]))
+		}
+		start = end
+	}
+	return b.String()
+}
+
+func (r *searchRepo) search(query repository.SearchQuery) []models.SearchResult {
+	terms := searchTerms(query.Text)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []models.SearchResult
+	for _, ticket := range r.s.d.tickets {
+		if ticket.DeletedAt != nil || (query.UserID != nil && ticket.UserID != *query.UserID) {
+			continue
+		}
+
+		rank := countMatches(ticket.Title+"\n"+ticket.Description, terms)
+		snippet := ticket.Description
+		var messageID *int
+		best := 0
+		for _, message := range r.s.d.messages {
+			if message.TicketID != ticket.ID || (query.WithoutNotes && message.Kind == models.MessageKindNote) {
+				continue
+			}
+			if n := countMatches(message.Message, terms); n > best || (n == best && n > 0 && message.ID < *messageID) {
+				id := message.ID
+				best, messageID, snippet = n, &id, message.Message
+			}
+		}
+		if rank+best == 0 {
+			continue
+		}
+
+		results = append(results, models.SearchResult{
+			Ticket:         ticket,
+			Rank:           float64(rank + best),
+			MessageID:      messageID,
+			TitleHighlight: highlightTerms(ticket.Title, terms),
+			Snippet:        highlightTerms(snippet, terms),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		if !results[i].Ticket.CreatedAt.Equal(results[j].Ticket.CreatedAt) {
+			return results[i].Ticket.CreatedAt.After(results[j].Ticket.CreatedAt)
+		}
+		return results[i].Ticket.ID > results[j].Ticket.ID
+	})
+	return results
+}
+
+func (r *searchRepo) Tickets(ctx context.Context, query repository.SearchQuery) ([]models.SearchResult, error) {
+	defer r.s.lock()()
+
+	return paginate(r.search(query), query.Limit, query.Offset), nil
+}
+
+func (r *searchRepo) Count(ctx context.Context, query repository.SearchQuery) (int, error) {
+	defer r.s.lock()()
+
+	return len(r.search(query)), nil
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"sync"
+)
+
+// data содержит все записи хранилища
+type data struct {
+	tickets      map[int]models.Ticket
+	messages     map[int]models.TicketMessage
+	photos       map[int]models.TicketPhoto
+	history      map[int64]models.TicketHistoryEntry
+	links        map[int64]models.TicketLink
+	ratings      map[int]models.TicketRating
+	users        map[int64]models.User
+	agents       map[int64]models.Agent
+	canned       map[int64]models.CannedResponse
+	customFields map[int64]models.CustomField
+	cursors      map[string]int64
+	escalations  map[models.Escalation]struct{}
+	outbox       map[int64]models.OutboxEntry
+
+	ticketSeq      int
+	messageSeq     int
+	photoSeq       int
+	historySeq     int64
+	linkSeq        int64
+	outboxSeq      int64
+	cannedSeq      int64
+	customFieldSeq int64
+}
+
+func newData() *data {
+	return &data{
+		tickets:      make(map[int]models.Ticket),
+		messages:     make(map[int]models.TicketMessage),
+		photos:       make(map[int]models.TicketPhoto),
+		history:      make(map[int64]models.TicketHistoryEntry),
+		links:        make(map[int64]models.TicketLink),
+		ratings:      make(map[int]models.TicketRating),
+		users:        make(map[int64]models.User),
+		agents:       make(map[int64]models.Agent),
+		canned:       make(map[int64]models.CannedResponse),
+		customFields: make(map[int64]models.CustomField),
+		cursors:      make(map[string]int64),
+		escalations:  make(map[models.Escalation]struct{}),
+		outbox:       make(map[int64]models.OutboxEntry),
+	}
+}
+
+// clone делает снимок данных для отката транзакции
+func (d *data) clone() *data {
+	c := *d
+	c.tickets = cloneMap(d.tickets)
+	c.messages = cloneMap(d.messages)
+	c.photos = cloneMap(d.photos)
+	c.history = cloneMap(d.history)
+	c.links = cloneMap(d.links)
+	c.ratings = cloneMap(d.ratings)
+	c.users = cloneMap(d.users)
+	c.agents = cloneMap(d.agents)
+	c.canned = cloneMap(d.canned)
+	c.customFields = cloneMap(d.customFields)
+	c.cursors = cloneMap(d.cursors)
+	c.escalations = cloneMap(d.escalations)
+	c.outbox = cloneMap(d.outbox)
+	return &c
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// Store реализует repository.Store в памяти процесса.
+// Предназначено для тестов и локального запуска без базы данных.
+type Store struct {
+	mu *sync.Mutex
+	d  *data
+	tx bool
+}
+
+// NewStore создает пустое хранилище в памяти
+func NewStore() *Store {
+	return &Store{mu: &sync.Mutex{}, d: newData()}
+}
+
+func (s *Store) Tickets() repository.TicketRepository   { return &ticketRepo{s: s} }
+func (s *Store) Messages() repository.MessageRepository { return &messageRepo{s: s} }
+func (s *Store) Photos() repository.PhotoRepository     { return &photoRepo{s: s} }
+func (s *Store) History() repository.HistoryRepository  { return &historyRepo{s: s} }
+func (s *Store) Links() repository.LinkRepository       { return &linkRepo{s: s} }
+func (s *Store) Ratings() repository.RatingRepository   { return &ratingRepo{s: s} }
+func (s *Store) Users() repository.UserRepository       { return &userRepo{s: s} }
+func (s *Store) Agents() repository.AgentRepository     { return &agentRepo{s: s} }
+func (s *Store) CannedResponses() repository.CannedResponseRepository {
+	return &cannedRepo{s: s}
+}
+func (s *Store) CustomFields() repository.CustomFieldRepository {
+	return &customFieldRepo{s: s}
+}
+func (s *Store) Routing() repository.RoutingRepository        { return &routingRepo{s: s} }
+func (s *Store) Escalations() repository.EscalationRepository { return &escalationRepo{s: s} }
+func (s *Store) Locks() repository.LockRepository             { return &lockRepo{s: s} }
+func (s *Store) Search() repository.SearchRepository          { return &searchRepo{s: s} }
+func (s *Store) Outbox() repository.OutboxRepository          { return &outboxRepo{s: s} }
+
+// InTx выполняет fn под общей блокировкой и восстанавливает данные при ошибке.
+// Вложенный вызов восстанавливает только изменения, сделанные в fn.
+func (s *Store) InTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	if s.tx {
+		snapshot := s.d.clone()
+		if err := fn(s); err != nil {
+			*s.d = *snapshot
+			return err
+		}
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.d.clone()
+	if err := fn(&Store{mu: s.mu, d: s.d, tx: true}); err != nil {
+		*s.d = *snapshot
+		return err
+	}
+	return nil
+}
+
+// lock захватывает блокировку, если вызов происходит вне транзакции
+func (s *Store) lock() func() {
+	if s.tx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
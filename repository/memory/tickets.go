@@ -0,0 +1,389 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+)
+
+type ticketRepo struct {
+	s *Store
+}
+
+func (d *data) matchTicket(ticket models.Ticket, filter repository.TicketFilter) bool {
+	if (ticket.DeletedAt != nil) != filter.Deleted {
+		return false
+	}
+	if filter.Status != "" && ticket.Status != filter.Status {
+		return false
+	}
+	if filter.Priority != "" && ticket.Priority != filter.Priority {
+		return false
+	}
+	if len(filter.Categories) > 0 && !containsString(filter.Categories, ticket.Category) {
+		return false
+	}
+	if filter.UserID != nil && ticket.UserID != *filter.UserID {
+		return false
+	}
+	if filter.AssigneeID != nil && (ticket.AssigneeID == nil || *ticket.AssigneeID != *filter.AssigneeID) {
+		return false
+	}
+	if filter.Unassigned && ticket.AssigneeID != nil {
+		return false
+	}
+	if filter.SLA != nil && ticket.ComputeSLAStatus(filter.SLA.Now) != filter.SLA.Status {
+		return false
+	}
+	if filter.CreatedFrom != nil && ticket.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && !ticket.CreatedAt.Before(*filter.CreatedTo) {
+		return false
+	}
+	if filter.ClosedFrom != nil && (ticket.ClosedAt == nil || ticket.ClosedAt.Before(*filter.ClosedFrom)) {
+		return false
+	}
+	if filter.ClosedTo != nil && (ticket.ClosedAt == nil || !ticket.ClosedAt.Before(*filter.ClosedTo)) {
+		return false
+	}
+	for key, value := range filter.CustomFields {
+		if stored, ok := ticket.CustomFields[key]; !ok || stored != value {
+			return false
+		}
+	}
+	if filter.Unanswered != nil && d.unanswered(ticket.ID) != *filter.Unanswered {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// unanswered сообщает, что последнее сообщение тикета от клиента; заметки не учитываются
+func (d *data) unanswered(ticketID int) bool {
+	var last *models.TicketMessage
+	for _, message := range d.messages {
+		if message.TicketID != ticketID || message.Kind == models.MessageKindNote {
+			continue
+		}
+		if last == nil || message.CreatedAt.After(last.CreatedAt) || (message.CreatedAt.Equal(last.CreatedAt) && message.ID > last.ID) {
+			m := message
+			last = &m
+		}
+	}
+	return last != nil && last.SenderType == "user"
+}
+
+// compareTickets сравнивает тикеты по полю сортировки так же, как ticketSortExpressions в postgres
+func compareTickets(a, b models.Ticket, field string) int {
+	switch field {
+	case repository.TicketSortCreatedAt:
+		return compareTimes(&a.CreatedAt, &b.CreatedAt)
+	case repository.TicketSortClosedAt:
+		return compareTimes(a.ClosedAt, b.ClosedAt)
+	case repository.TicketSortPriority:
+		return models.PriorityRank(a.Priority) - models.PriorityRank(b.Priority)
+	case repository.TicketSortStatus:
+		return models.StatusRank(a.Status) - models.StatusRank(b.Status)
+	case repository.TicketSortCategory:
+		return strings.Compare(a.Category, b.Category)
+	case repository.TicketSortUserID:
+		return compareInts(a.UserID, b.UserID)
+	case repository.TicketSortFirstResponseDue:
+		return compareTimes(a.FirstResponseDue, b.FirstResponseDue)
+	case repository.TicketSortResolutionDue:
+		return compareTimes(a.ResolutionDue, b.ResolutionDue)
+	case repository.TicketSortDeletedAt:
+		return compareTimes(a.DeletedAt, b.DeletedAt)
+	}
+	return 0
+}
+
+// compareTimes сравнивает даты, отсутствующая дата больше любой
+func compareTimes(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return a.Compare(*b)
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// sortTickets упорядочивает тикеты по sort, при равенстве — по ID в том же направлении
+func sortTickets(tickets []models.Ticket, sortBy repository.TicketSort) {
+	if !repository.ValidTicketSortField(sortBy.Field) {
+		sortBy = repository.DefaultTicketSort
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		c := compareTickets(tickets[i], tickets[j], sortBy.Field)
+		if c == 0 {
+			c = tickets[i].ID - tickets[j].ID
+		}
+		if sortBy.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// paginate применяет limit/offset к уже отсортированному срезу
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset > 0 {
+		if offset >= len(items) {
+			return nil
+		}
+		items = items[offset:]
+	}
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// paginatePage применяет к уже отсортированному срезу курсор страницы или limit/offset.
+// key возвращает курсор элемента, desc — направление сортировки среза.
+func paginatePage[T any](items []T, page repository.Page, kind string, desc bool, key func(T) repository.Cursor) []T {
+	// position сравнивает элемент с курсором в порядке среза
+	position := func(item T, cursor repository.Cursor) int {
+		c := key(item).Compare(kind, cursor)
+		if desc {
+			return -c
+		}
+		return c
+	}
+
+	switch {
+	case page.After != nil:
+		start := sort.Search(len(items), func(i int) bool { return position(items[i], *page.After) > 0 })
+		return paginate(items[start:], page.Limit, 0)
+	case page.Before != nil:
+		end := sort.Search(len(items), func(i int) bool { return position(items[i], *page.Before) >= 0 })
+		items = items[:end]
+		if page.Limit > 0 && page.Limit < len(items) {
+			items = items[len(items)-page.Limit:]
+		}
+		return items
+	}
+	return paginate(items, page.Limit, page.Offset)
+}
+
+func (r *ticketRepo) List(ctx context.Context, filter repository.TicketFilter) ([]models.Ticket, error) {
+	defer r.s.lock()()
+
+	var tickets []models.Ticket
+	for _, ticket := range r.s.d.tickets {
+		if r.s.d.matchTicket(ticket, filter) {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	sortBy := filter.Sort
+	if !repository.ValidTicketSortField(sortBy.Field) {
+		sortBy = repository.DefaultTicketSort
+	}
+	sortTickets(tickets, sortBy)
+
+	return paginatePage(tickets, filter.Page, repository.TicketSortKind(sortBy.Field), sortBy.Desc, func(ticket models.Ticket) repository.Cursor {
+		return repository.TicketCursor(ticket, sortBy.Field)
+	}), nil
+}
+
+func (r *ticketRepo) Count(ctx context.Context, filter repository.TicketFilter) (int, error) {
+	defer r.s.lock()()
+
+	count := 0
+	for _, ticket := range r.s.d.tickets {
+		if r.s.d.matchTicket(ticket, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *ticketRepo) GetByID(ctx context.Context, id int) (*models.Ticket, error) {
+	defer r.s.lock()()
+
+	ticket, ok := r.s.d.tickets[id]
+	if !ok || ticket.DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return &ticket, nil
+}
+
+// GetForUpdate не требует отдельной блокировки: транзакция InTx держит общую
+func (r *ticketRepo) GetForUpdate(ctx context.Context, id int) (*models.Ticket, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *ticketRepo) Exists(ctx context.Context, id int) (bool, error) {
+	defer r.s.lock()()
+
+	ticket, ok := r.s.d.tickets[id]
+	return ok && ticket.DeletedAt == nil, nil
+}
+
+func (r *ticketRepo) Create(ctx context.Context, ticket *models.Ticket) error {
+	defer r.s.lock()()
+
+	r.s.d.ticketSeq++
+	ticket.ID = r.s.d.ticketSeq
+	r.s.d.tickets[ticket.ID] = *ticket
+	return nil
+}
+
+func (r *ticketRepo) Update(ctx context.Context, ticket *models.Ticket) error {
+	defer r.s.lock()()
+
+	current, ok := r.s.d.tickets[ticket.ID]
+	if !ok || current.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+
+	current.Status = ticket.Status
+	current.Priority = ticket.Priority
+	current.Category = ticket.Category
+	current.ClosedAt = ticket.ClosedAt
+	current.ResolvedAt = ticket.ResolvedAt
+	current.ReopenedAt = ticket.ReopenedAt
+	current.AssigneeID = ticket.AssigneeID
+	current.FirstResponseAt = ticket.FirstResponseAt
+	current.FirstResponseDue = ticket.FirstResponseDue
+	current.ResolutionDue = ticket.ResolutionDue
+	current.FirstResponseRiskAt = ticket.FirstResponseRiskAt
+	current.ResolutionRiskAt = ticket.ResolutionRiskAt
+	current.WaitingSince = ticket.WaitingSince
+	current.StaleRemindedAt = ticket.StaleRemindedAt
+	current.AutoClosedAt = ticket.AutoClosedAt
+	current.MergedIntoID = ticket.MergedIntoID
+	r.s.d.tickets[ticket.ID] = current
+	return nil
+}
+
+func (r *ticketRepo) SoftDelete(ctx context.Context, id int, at time.Time, by *int64) error {
+	defer r.s.lock()()
+
+	ticket, ok := r.s.d.tickets[id]
+	if !ok || ticket.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+	ticket.DeletedAt = &at
+	ticket.DeletedBy = by
+	r.s.d.tickets[id] = ticket
+	return nil
+}
+
+func (r *ticketRepo) GetDeleted(ctx context.Context, id int) (*models.Ticket, error) {
+	defer r.s.lock()()
+
+	ticket, ok := r.s.d.tickets[id]
+	if !ok || ticket.DeletedAt == nil {
+		return nil, repository.ErrNotFound
+	}
+	return &ticket, nil
+}
+
+func (r *ticketRepo) Restore(ctx context.Context, id int) error {
+	defer r.s.lock()()
+
+	ticket, ok := r.s.d.tickets[id]
+	if !ok || ticket.DeletedAt == nil {
+		return repository.ErrNotFound
+	}
+	ticket.DeletedAt = nil
+	ticket.DeletedBy = nil
+	r.s.d.tickets[id] = ticket
+	return nil
+}
+
+func (r *ticketRepo) ListDeleted(ctx context.Context, before time.Time) ([]models.Ticket, error) {
+	defer r.s.lock()()
+
+	var tickets []models.Ticket
+	for _, ticket := range r.s.d.tickets {
+		if ticket.DeletedAt != nil && ticket.DeletedAt.Before(before) {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	sort.Slice(tickets, func(i, j int) bool {
+		if !tickets[i].DeletedAt.Equal(*tickets[j].DeletedAt) {
+			return tickets[i].DeletedAt.Before(*tickets[j].DeletedAt)
+		}
+		return tickets[i].ID < tickets[j].ID
+	})
+	return tickets, nil
+}
+
+func (r *ticketRepo) CountActiveByAssignee(ctx context.Context) (map[int64]int, error) {
+	defer r.s.lock()()
+
+	counts := make(map[int64]int)
+	for _, ticket := range r.s.d.tickets {
+		if ticket.AssigneeID != nil && ticket.DeletedAt == nil && models.IsActiveStatus(ticket.Status) {
+			counts[*ticket.AssigneeID]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *ticketRepo) ListActiveByAssignee(ctx context.Context, agentID int64) ([]models.Ticket, error) {
+	defer r.s.lock()()
+
+	var tickets []models.Ticket
+	for _, ticket := range r.s.d.tickets {
+		if ticket.AssigneeID != nil && *ticket.AssigneeID == agentID && ticket.DeletedAt == nil && models.IsActiveStatus(ticket.Status) {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].ID < tickets[j].ID })
+	return tickets, nil
+}
+
+func (r *ticketRepo) ListWaiting(ctx context.Context, before time.Time) ([]models.Ticket, error) {
+	defer r.s.lock()()
+
+	var tickets []models.Ticket
+	for _, ticket := range r.s.d.tickets {
+		if ticket.WaitingSince != nil && ticket.WaitingSince.Before(before) && ticket.DeletedAt == nil && models.IsActiveStatus(ticket.Status) {
+			tickets = append(tickets, ticket)
+		}
+	}
+
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].WaitingSince.Before(*tickets[j].WaitingSince) })
+	return tickets, nil
+}
+
+func (r *ticketRepo) Delete(ctx context.Context, id int) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.tickets[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.d.tickets, id)
+	return nil
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+type userRepo struct {
+	s *Store
+}
+
+func (r *userRepo) List(ctx context.Context, page repository.Page) ([]models.User, error) {
+	defer r.s.lock()()
+
+	users := make([]models.User, 0, len(r.s.d.users))
+	for _, user := range r.s.d.users {
+		users = append(users, user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	return paginatePage(users, page, repository.KeyNone, false, func(user models.User) repository.Cursor {
+		return repository.Cursor{ID: user.ID}
+	}), nil
+}
+
+func (r *userRepo) Count(ctx context.Context) (int, error) {
+	defer r.s.lock()()
+
+	return len(r.s.d.users), nil
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	defer r.s.lock()()
+
+	user, ok := r.s.d.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *userRepo) Exists(ctx context.Context, id int64) (bool, error) {
+	defer r.s.lock()()
+
+	_, ok := r.s.d.users[id]
+	return ok, nil
+}
+
+func (r *userRepo) Create(ctx context.Context, user *models.User) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.users[user.ID]; ok {
+		return fmt.Errorf("пользователь %d уже существует", user.ID)
+	}
+	r.s.d.users[user.ID] = *user
+	return nil
+}
+
+func (r *userRepo) Update(ctx context.Context, user *models.User) error {
+	defer r.s.lock()()
+
+	if _, ok := r.s.d.users[user.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.d.users[user.ID] = *user
+	return nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"support_front_api/models"
+	"time"
+)
+
+// Page задает страницу выборки: смещение Offset или курсор After/Before (keyset-пагинация).
+// С курсором Offset не используется. Before возвращает Limit записей, непосредственно
+// предшествующих курсору, в обычном порядке сортировки. Нулевой Limit — без ограничения.
+type Page struct {
+	Limit  int
+	Offset int
+	After  *Cursor
+	Before *Cursor
+}
+
+// Cursor — граница страницы: значение ключа сортировки и ID записи.
+// Выборка продолжается строго после (или до) этой пары в порядке сортировки списка.
+type Cursor struct {
+	// Key — значение ключа в текстовом виде (см. KeyKind); пустое, если список упорядочен только по ID
+	Key string
+	ID  int64
+}
+
+// Типы ключей сортировки в курсоре
+const (
+	// KeyNone — список упорядочен только по ID
+	KeyNone = ""
+	KeyInt  = "int"
+	KeyText = "text"
+	// KeyTime — время в RFC 3339 (UTC, с долями секунды) или KeyInfinity
+	KeyTime = "time"
+)
+
+// KeyInfinity — ключ отсутствующей даты: такие записи идут после записей с датой
+const KeyInfinity = "infinity"
+
+// TimeKey записывает дату ключом KeyTime
+func TimeKey(t *time.Time) string {
+	if t == nil {
+		return KeyInfinity
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// ValidKey проверяет, что значение ключа соответствует типу
+func ValidKey(kind, key string) bool {
+	switch kind {
+	case KeyNone:
+		return key == ""
+	case KeyInt:
+		_, err := strconv.ParseInt(key, 10, 64)
+		return err == nil
+	case KeyTime:
+		_, err := parseTimeKey(key)
+		return err == nil
+	case KeyText:
+		return true
+	}
+	return false
+}
+
+// parseTimeKey разбирает ключ KeyTime; nil — KeyInfinity
+func parseTimeKey(key string) (*time.Time, error) {
+	if key == KeyInfinity {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, key)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CompareKeys сравнивает значения ключей одного типа; неразбираемые значения считаются равными
+func CompareKeys(kind, a, b string) int {
+	switch kind {
+	case KeyInt:
+		x, errX := strconv.ParseInt(a, 10, 64)
+		y, errY := strconv.ParseInt(b, 10, 64)
+		if errX != nil || errY != nil {
+			return 0
+		}
+		return compareInt64(x, y)
+	case KeyTime:
+		x, errX := parseTimeKey(a)
+		y, errY := parseTimeKey(b)
+		switch {
+		case errX != nil || errY != nil || (x == nil && y == nil):
+			return 0
+		case x == nil:
+			return 1
+		case y == nil:
+			return -1
+		}
+		return x.Compare(*y)
+	case KeyText:
+		return strings.Compare(a, b)
+	}
+	return 0
+}
+
+// Compare сравнивает курсоры по ключу, при равенстве — по ID
+func (c Cursor) Compare(kind string, other Cursor) int {
+	if r := CompareKeys(kind, c.Key, other.Key); r != 0 {
+		return r
+	}
+	return compareInt64(c.ID, other.ID)
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ticketSortKinds — типы ключей полей сортировки тикетов
+var ticketSortKinds = map[string]string{
+	TicketSortID:               KeyNone,
+	TicketSortCreatedAt:        KeyTime,
+	TicketSortClosedAt:         KeyTime,
+	TicketSortPriority:         KeyInt,
+	TicketSortStatus:           KeyInt,
+	TicketSortCategory:         KeyText,
+	TicketSortUserID:           KeyInt,
+	TicketSortFirstResponseDue: KeyTime,
+	TicketSortResolutionDue:    KeyTime,
+	TicketSortDeletedAt:        KeyTime,
+}
+
+// TicketSortKind возвращает тип ключа поля сортировки тикетов
+func TicketSortKind(field string) string {
+	return ticketSortKinds[field]
+}
+
+// TicketCursor возвращает курсор тикета для поля сортировки. Приоритет и статус записываются
+// позицией в справочнике, начиная с 1, как array_position в postgres.
+func TicketCursor(ticket models.Ticket, field string) Cursor {
+	cursor := Cursor{ID: int64(ticket.ID)}
+	switch field {
+	case TicketSortCreatedAt:
+		cursor.Key = TimeKey(&ticket.CreatedAt)
+	case TicketSortClosedAt:
+		cursor.Key = TimeKey(ticket.ClosedAt)
+	case TicketSortPriority:
+		cursor.Key = strconv.Itoa(models.PriorityRank(ticket.Priority) + 1)
+	case TicketSortStatus:
+		cursor.Key = strconv.Itoa(models.StatusRank(ticket.Status) + 1)
+	case TicketSortCategory:
+		cursor.Key = ticket.Category
+	case TicketSortUserID:
+		cursor.Key = strconv.FormatInt(ticket.UserID, 10)
+	case TicketSortFirstResponseDue:
+		cursor.Key = TimeKey(ticket.FirstResponseDue)
+	case TicketSortResolutionDue:
+		cursor.Key = TimeKey(ticket.ResolutionDue)
+	case TicketSortDeletedAt:
+		cursor.Key = TimeKey(ticket.DeletedAt)
+	}
+	return cursor
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"support_front_api/models"
+	"testing"
+	"time"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		kind, key string
+		want      bool
+	}{
+		{KeyNone, "", true},
+		{KeyNone, "1", false},
+		{KeyInt, "42", true},
+		{KeyInt, "-3", true},
+		{KeyInt, "4.2", false},
+		{KeyInt, "", false},
+		{KeyText, "", true},
+		{KeyText, "billing", true},
+		{KeyTime, "2024-03-01T12:00:00.5Z", true},
+		{KeyTime, KeyInfinity, true},
+		{KeyTime, "2024-03-01", false},
+		{"unknown", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidKey(tt.kind, tt.key); got != tt.want {
+			t.Errorf("ValidKey(%q, %q) = %v, ожидалось %v", tt.kind, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCompareKeys(t *testing.T) {
+	earlier := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Millisecond)
+
+	tests := []struct {
+		name       string
+		kind, a, b string
+		want       int
+	}{
+		{"числа, а не строки", KeyInt, "9", "10", -1},
+		{"равные числа", KeyInt, "10", "10", 0},
+		{"текст", KeyText, "billing", "access", 1},
+		{"время с долями секунды", KeyTime, TimeKey(&earlier), TimeKey(&later), -1},
+		{"время в разных поясах", KeyTime, "2024-03-01T15:00:00+03:00", TimeKey(&earlier), 0},
+		{"дата раньше бесконечности", KeyTime, TimeKey(&later), KeyInfinity, -1},
+		{"бесконечность позже даты", KeyTime, KeyInfinity, TimeKey(&earlier), 1},
+		{"две бесконечности", KeyTime, KeyInfinity, KeyInfinity, 0},
+		{"неразбираемое значение", KeyInt, "x", "1", 0},
+		{"без ключа", KeyNone, "", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareKeys(tt.kind, tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareKeys(%q, %q, %q) = %d, ожидалось %d", tt.kind, tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorCompare(t *testing.T) {
+	tests := []struct {
+		kind string
+		a, b Cursor
+		want int
+	}{
+		{KeyInt, Cursor{Key: "1", ID: 9}, Cursor{Key: "2", ID: 1}, -1},
+		{KeyInt, Cursor{Key: "2", ID: 1}, Cursor{Key: "2", ID: 5}, -1},
+		{KeyInt, Cursor{Key: "2", ID: 5}, Cursor{Key: "2", ID: 5}, 0},
+		{KeyNone, Cursor{ID: 7}, Cursor{ID: 3}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.kind, tt.b); got != tt.want {
+			t.Errorf("%+v.Compare(%q, %+v) = %d, ожидалось %d", tt.a, tt.kind, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTicketCursor(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("MSK", 3*3600))
+	ticket := models.Ticket{
+		ID:        12,
+		UserID:    77,
+		Status:    models.Statuses[1],
+		Priority:  models.Priorities[0],
+		Category:  "billing",
+		CreatedAt: created,
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{TicketSortID, ""},
+		{TicketSortCreatedAt, "2024-03-01T06:30:00Z"},
+		{TicketSortClosedAt, KeyInfinity},
+		{TicketSortPriority, "1"},
+		{TicketSortStatus, "2"},
+		{TicketSortCategory, "billing"},
+		{TicketSortUserID, "77"},
+		{TicketSortResolutionDue, KeyInfinity},
+	}
+
+	for _, tt := range tests {
+		cursor := TicketCursor(ticket, tt.field)
+		if cursor.Key != tt.want || cursor.ID != 12 {
+			t.Errorf("TicketCursor(%q) = %+v, ожидался ключ %q", tt.field, cursor, tt.want)
+		}
+		if !ValidKey(TicketSortKind(tt.field), cursor.Key) {
+			t.Errorf("ключ %q не соответствует типу поля %q", cursor.Key, tt.field)
+		}
+	}
+}
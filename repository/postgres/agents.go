@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"support_front_api/models"
+	"support_front_api/repository"
+
+	"github.com/lib/pq"
+)
+
+const agentColumns = "id, full_name, email, is_active, skills, on_shift, created_at"
+
+type agentRepo struct {
+	q querier
+}
+
+func scanAgent(row scanner) (*models.Agent, error) {
+	var agent models.Agent
+	if err := row.Scan(
+		&agent.ID,
+		&agent.FullName,
+		&agent.Email,
+		&agent.IsActive,
+		pq.Array(&agent.Skills),
+		&agent.OnShift,
+		&agent.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// skillsArray возвращает навыки для записи в колонку NOT NULL: nil-срез pq записал бы как NULL
+func skillsArray(skills []string) interface{} {
+	if skills == nil {
+		skills = []string{}
+	}
+	return pq.Array(skills)
+}
+
+func scanAgents(rows *sql.Rows) ([]models.Agent, error) {
+	defer rows.Close()
+
+	var agents []models.Agent
+	for rows.Next() {
+		agent, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, *agent)
+	}
+
+	return agents, rows.Err()
+}
+
+func (r *agentRepo) List(ctx context.Context, page repository.Page) ([]models.Agent, error) {
+	query, params := keyset{id: "id"}.apply("SELECT "+agentColumns+" FROM support_agents", false, page, nil)
+	rows, err := r.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	agents, err := scanAgents(rows)
+	if page.Before != nil {
+		reverse(agents)
+	}
+	return agents, err
+}
+
+func (r *agentRepo) ListAvailable(ctx context.Context) ([]models.Agent, error) {
+	rows, err := r.q.QueryContext(ctx,
+		"SELECT "+agentColumns+" FROM support_agents WHERE is_active AND on_shift ORDER BY id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAgents(rows)
+}
+
+func (r *agentRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM support_agents").Scan(&count)
+	return count, err
+}
+
+func (r *agentRepo) GetByID(ctx context.Context, id int64) (*models.Agent, error) {
+	agent, err := scanAgent(r.q.QueryRowContext(ctx, "SELECT "+agentColumns+" FROM support_agents WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return agent, err
+}
+
+func (r *agentRepo) Exists(ctx context.Context, id int64) (bool, error) {
+	var exists bool
+	err := r.q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM support_agents WHERE id = $1)", id).Scan(&exists)
+	return exists, err
+}
+
+func (r *agentRepo) Create(ctx context.Context, agent *models.Agent) error {
+	_, err := r.q.ExecContext(ctx,
+		"INSERT INTO support_agents ("+agentColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		agent.ID, agent.FullName, agent.Email, agent.IsActive, skillsArray(agent.Skills), agent.OnShift, agent.CreatedAt,
+	)
+	return err
+}
+
+func (r *agentRepo) Update(ctx context.Context, agent *models.Agent) error {
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE support_agents SET full_name = $1, email = $2, is_active = $3, skills = $4, on_shift = $5 WHERE id = $6",
+		agent.FullName, agent.Email, agent.IsActive, skillsArray(agent.Skills), agent.OnShift, agent.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *agentRepo) Delete(ctx context.Context, id int64) error {
+	result, err := r.q.ExecContext(ctx, "DELETE FROM support_agents WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
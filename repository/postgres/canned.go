@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+const cannedColumns = "id, title, body, category, owner_id, created_at, updated_at"
+
+// cannedKeyset — шаблоны сгруппированы по категории
+var cannedKeyset = keyset{expr: "category", kind: repository.KeyText, id: "id"}
+
+type cannedRepo struct {
+	q querier
+}
+
+func scanCanned(row scanner) (*models.CannedResponse, error) {
+	var response models.CannedResponse
+	var ownerID sql.NullInt64
+
+	if err := row.Scan(
+		&response.ID,
+		&response.Title,
+		&response.Body,
+		&response.Category,
+		&ownerID,
+		&response.CreatedAt,
+		&response.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if ownerID.Valid {
+		response.OwnerID = &ownerID.Int64
+	}
+	return &response, nil
+}
+
+// cannedWhere возвращает условие выборки шаблонов по фильтру и его параметры
+func cannedWhere(filter repository.CannedResponseFilter) (string, []interface{}) {
+	var conds []string
+	var params []interface{}
+
+	// param добавляет параметр запроса и возвращает его плейсхолдер
+	param := func(value interface{}) string {
+		params = append(params, value)
+		return "$" + strconv.Itoa(len(params))
+	}
+
+	switch filter.Scope {
+	case repository.CannedScopeShared:
+		conds = append(conds, "owner_id IS NULL")
+	case repository.CannedScopePersonal:
+		conds = append(conds, "owner_id = "+param(filter.VisibleTo))
+	default:
+		conds = append(conds, "(owner_id IS NULL OR owner_id = "+param(filter.VisibleTo)+")")
+	}
+	if filter.Category != "" {
+		conds = append(conds, "category IN ('', "+param(filter.Category)+")")
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), params
+}
+
+func (r *cannedRepo) List(ctx context.Context, filter repository.CannedResponseFilter) ([]models.CannedResponse, error) {
+	where, params := cannedWhere(filter)
+	query, params := cannedKeyset.apply("SELECT "+cannedColumns+" FROM canned_responses"+where, true, filter.Page, params)
+
+	rows, err := r.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var responses []models.CannedResponse
+	for rows.Next() {
+		response, err := scanCanned(rows)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+
+	if filter.Before != nil {
+		reverse(responses)
+	}
+	return responses, rows.Err()
+}
+
+func (r *cannedRepo) Count(ctx context.Context, filter repository.CannedResponseFilter) (int, error) {
+	where, params := cannedWhere(filter)
+
+	var count int
+	err := r.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM canned_responses"+where, params...).Scan(&count)
+	return count, err
+}
+
+func (r *cannedRepo) GetByID(ctx context.Context, id int64) (*models.CannedResponse, error) {
+	response, err := scanCanned(r.q.QueryRowContext(ctx, "SELECT "+cannedColumns+" FROM canned_responses WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return response, err
+}
+
+func (r *cannedRepo) Create(ctx context.Context, response *models.CannedResponse) error {
+	return r.q.QueryRowContext(ctx,
+		"INSERT INTO canned_responses (title, body, category, owner_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		response.Title, response.Body, response.Category, response.OwnerID, response.CreatedAt, response.UpdatedAt,
+	).Scan(&response.ID)
+}
+
+func (r *cannedRepo) Update(ctx context.Context, response *models.CannedResponse) error {
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE canned_responses SET title = $1, body = $2, category = $3, updated_at = $4 WHERE id = $5",
+		response.Title, response.Body, response.Category, response.UpdatedAt, response.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *cannedRepo) Delete(ctx context.Context, id int64) error {
+	result, err := r.q.ExecContext(ctx, "DELETE FROM canned_responses WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"support_front_api/models"
+	"support_front_api/repository"
+
+	"github.com/lib/pq"
+)
+
+const customFieldColumns = "id, category, key, label, type, required, options, created_at"
+
+type customFieldRepo struct {
+	q querier
+}
+
+func scanCustomField(row scanner) (*models.CustomField, error) {
+	var field models.CustomField
+	var options pq.StringArray
+
+	if err := row.Scan(
+		&field.ID,
+		&field.Category,
+		&field.Key,
+		&field.Label,
+		&field.Type,
+		&field.Required,
+		&options,
+		&field.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(options) > 0 {
+		field.Options = options
+	}
+	return &field, nil
+}
+
+// customFieldsJSON записывает значения дополнительных полей тикета объектом JSON
+func customFieldsJSON(values map[string]interface{}) ([]byte, error) {
+	if len(values) == 0 {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(values)
+}
+
+func (r *customFieldRepo) List(ctx context.Context, category string) ([]models.CustomField, error) {
+	query := "SELECT " + customFieldColumns + " FROM ticket_custom_fields"
+	var params []interface{}
+	if category != "" {
+		query += " WHERE category = $1"
+		params = append(params, category)
+	}
+
+	rows, err := r.q.QueryContext(ctx, query+" ORDER BY category, id", params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fields []models.CustomField
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, *field)
+	}
+
+	return fields, rows.Err()
+}
+
+func (r *customFieldRepo) GetByID(ctx context.Context, id int64) (*models.CustomField, error) {
+	field, err := scanCustomField(r.q.QueryRowContext(ctx, "SELECT "+customFieldColumns+" FROM ticket_custom_fields WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return field, err
+}
+
+func (r *customFieldRepo) Create(ctx context.Context, field *models.CustomField) (bool, error) {
+	// Уникальный индекс (category, key) не дает добавить поле с тем же ключом дважды
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO ticket_custom_fields (category, key, label, type, required, options, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (category, key) DO NOTHING
+		RETURNING id
+	`,
+		field.Category,
+		field.Key,
+		field.Label,
+		field.Type,
+		field.Required,
+		pq.StringArray(field.Options),
+		field.CreatedAt,
+	).Scan(&field.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *customFieldRepo) Update(ctx context.Context, field *models.CustomField) error {
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE ticket_custom_fields SET label = $1, required = $2, options = $3 WHERE id = $4",
+		field.Label, field.Required, pq.StringArray(field.Options), field.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *customFieldRepo) Delete(ctx context.Context, id int64) error {
+	result, err := r.q.ExecContext(ctx, "DELETE FROM ticket_custom_fields WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
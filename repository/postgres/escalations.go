@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"support_front_api/models"
+)
+
+type escalationRepo struct {
+	q querier
+}
+
+func (r *escalationRepo) Record(ctx context.Context, escalation models.Escalation) (bool, error) {
+	result, err := r.q.ExecContext(ctx,
+		"INSERT INTO ticket_escalations (ticket_id, deadline, level, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING",
+		escalation.TicketID, escalation.Deadline, escalation.Level, escalation.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *escalationRepo) Exists(ctx context.Context, escalation models.Escalation) (bool, error) {
+	var exists bool
+	err := r.q.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM ticket_escalations WHERE ticket_id = $1 AND deadline = $2 AND level = $3)",
+		escalation.TicketID, escalation.Deadline, escalation.Level,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r *escalationRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	_, err := r.q.ExecContext(ctx, "DELETE FROM ticket_escalations WHERE ticket_id = $1", ticketID)
+	return err
+}
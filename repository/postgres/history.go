@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+const historyColumns = "id, ticket_id, field, old_value, new_value, actor_role, actor_id, created_at"
+
+// historyKeyset — хронологический порядок истории тикета
+var historyKeyset = keyset{expr: "created_at", kind: repository.KeyTime, id: "id"}
+
+type historyRepo struct {
+	q querier
+}
+
+func scanHistoryEntry(row scanner) (*models.TicketHistoryEntry, error) {
+	var entry models.TicketHistoryEntry
+	var oldValue, newValue sql.NullString
+	var actorID sql.NullInt64
+
+	if err := row.Scan(
+		&entry.ID,
+		&entry.TicketID,
+		&entry.Field,
+		&oldValue,
+		&newValue,
+		&entry.ActorRole,
+		&actorID,
+		&entry.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if oldValue.Valid {
+		entry.OldValue = &oldValue.String
+	}
+	if newValue.Valid {
+		entry.NewValue = &newValue.String
+	}
+	if actorID.Valid {
+		entry.ActorID = &actorID.Int64
+	}
+
+	return &entry, nil
+}
+
+func (r *historyRepo) Add(ctx context.Context, entries ...models.TicketHistoryEntry) error {
+	for i := range entries {
+		entry := &entries[i]
+		err := r.q.QueryRowContext(ctx,
+			"INSERT INTO ticket_history (ticket_id, field, old_value, new_value, actor_role, actor_id, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+			entry.TicketID, entry.Field, entry.OldValue, entry.NewValue, entry.ActorRole, entry.ActorID, entry.CreatedAt,
+		).Scan(&entry.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *historyRepo) ListByTicket(ctx context.Context, ticketID int, page repository.Page) ([]models.TicketHistoryEntry, error) {
+	query, params := historyKeyset.apply("SELECT "+historyColumns+" FROM ticket_history WHERE ticket_id = $1", true, page, []interface{}{ticketID})
+
+	rows, err := r.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.TicketHistoryEntry
+	for rows.Next() {
+		entry, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	if page.Before != nil {
+		reverse(entries)
+	}
+	return entries, rows.Err()
+}
+
+func (r *historyRepo) CountByTicket(ctx context.Context, ticketID int) (int, error) {
+	var count int
+	err := r.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM ticket_history WHERE ticket_id = $1", ticketID).Scan(&count)
+	return count, err
+}
+
+func (r *historyRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	_, err := r.q.ExecContext(ctx, "DELETE FROM ticket_history WHERE ticket_id = $1", ticketID)
+	return err
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+const linkColumns = "id, ticket_id, linked_ticket_id, type, on_parent_resolved, created_at"
+
+type linkRepo struct {
+	q querier
+}
+
+func scanLink(row scanner) (*models.TicketLink, error) {
+	var link models.TicketLink
+	var onParentResolved sql.NullString
+
+	if err := row.Scan(
+		&link.ID,
+		&link.TicketID,
+		&link.LinkedTicketID,
+		&link.Type,
+		&onParentResolved,
+		&link.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	link.OnParentResolved = onParentResolved.String
+	return &link, nil
+}
+
+func (r *linkRepo) ListByTicket(ctx context.Context, ticketID int) ([]models.TicketLink, error) {
+	rows, err := r.q.QueryContext(ctx,
+		"SELECT "+linkColumns+" FROM ticket_links WHERE ticket_id = $1 OR linked_ticket_id = $1 ORDER BY id",
+		ticketID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []models.TicketLink
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
+
+func (r *linkRepo) GetByID(ctx context.Context, id int64) (*models.TicketLink, error) {
+	link, err := scanLink(r.q.QueryRowContext(ctx, "SELECT "+linkColumns+" FROM ticket_links WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return link, err
+}
+
+func (r *linkRepo) Create(ctx context.Context, link *models.TicketLink) (bool, error) {
+	// Уникальные индексы не дают связать пару тикетов дважды и дать тикету второго родителя
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO ticket_links (ticket_id, linked_ticket_id, type, on_parent_resolved, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT DO NOTHING
+		RETURNING id
+	`,
+		link.TicketID,
+		link.LinkedTicketID,
+		link.Type,
+		link.OnParentResolved,
+		link.CreatedAt,
+	).Scan(&link.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *linkRepo) Delete(ctx context.Context, id int64) error {
+	result, err := r.q.ExecContext(ctx, "DELETE FROM ticket_links WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *linkRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	_, err := r.q.ExecContext(ctx, "DELETE FROM ticket_links WHERE ticket_id = $1 OR linked_ticket_id = $1", ticketID)
+	return err
+}
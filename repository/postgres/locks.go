@@ -0,0 +1,14 @@
+package postgres
+
+import "context"
+
+type lockRepo struct {
+	q querier
+}
+
+// TryLock берет транзакционную advisory-блокировку: она снимается при завершении транзакции
+func (r *lockRepo) TryLock(ctx context.Context, key int64) (bool, error) {
+	var locked bool
+	err := r.q.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", key).Scan(&locked)
+	return locked, err
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"support_front_api/models"
+	"support_front_api/repository"
+
+	"github.com/lib/pq"
+)
+
+const messageColumns = "id, ticket_id, sender_type, sender_id, kind, message, created_at, mentions"
+
+// messageKeyset — хронологический порядок сообщений тикета
+var messageKeyset = keyset{expr: "created_at", kind: repository.KeyTime, id: "id"}
+
+type messageRepo struct {
+	q querier
+}
+
+func scanMessage(row scanner) (*models.TicketMessage, error) {
+	var message models.TicketMessage
+	if err := row.Scan(
+		&message.ID,
+		&message.TicketID,
+		&message.SenderType,
+		&message.SenderID,
+		&message.Kind,
+		&message.Message,
+		&message.CreatedAt,
+		(*pq.Int64Array)(&message.Mentions),
+	); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// messageWhere возвращает условие выборки сообщений тикета ($1 — ID тикета)
+func messageWhere(filter repository.MessageFilter) string {
+	if filter.WithoutNotes {
+		return " WHERE ticket_id = $1 AND kind <> '" + models.MessageKindNote + "'"
+	}
+	return " WHERE ticket_id = $1"
+}
+
+func (r *messageRepo) ListByTicket(ctx context.Context, ticketID int, filter repository.MessageFilter) ([]models.TicketMessage, error) {
+	query, params := messageKeyset.apply("SELECT "+messageColumns+" FROM ticket_messages"+messageWhere(filter), true, filter.Page, []interface{}{ticketID})
+	rows, err := r.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.TicketMessage
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *message)
+	}
+
+	if filter.Before != nil {
+		reverse(messages)
+	}
+	return messages, rows.Err()
+}
+
+func (r *messageRepo) CountByTicket(ctx context.Context, ticketID int, filter repository.MessageFilter) (int, error) {
+	var count int
+	err := r.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM ticket_messages"+messageWhere(filter), ticketID).Scan(&count)
+	return count, err
+}
+
+func (r *messageRepo) Create(ctx context.Context, message *models.TicketMessage) error {
+	return r.q.QueryRowContext(ctx,
+		"INSERT INTO ticket_messages (ticket_id, sender_type, sender_id, kind, message, created_at, mentions) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		message.TicketID, message.SenderType, message.SenderID, message.Kind, message.Message, message.CreatedAt, mentionsArray(message.Mentions),
+	).Scan(&message.ID)
+}
+
+// mentionsArray возвращает упоминания для записи в колонку NOT NULL: nil-срез pq записал бы как NULL
+func mentionsArray(mentions []int64) interface{} {
+	if mentions == nil {
+		mentions = []int64{}
+	}
+	return pq.Array(mentions)
+}
+
+func (r *messageRepo) MoveToTicket(ctx context.Context, fromID, toID int) (int, error) {
+	result, err := r.q.ExecContext(ctx, "UPDATE ticket_messages SET ticket_id = $1 WHERE ticket_id = $2", toID, fromID)
+	if err != nil {
+		return 0, err
+	}
+	moved, err := result.RowsAffected()
+	return int(moved), err
+}
+
+func (r *messageRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	_, err := r.q.ExecContext(ctx, "DELETE FROM ticket_messages WHERE ticket_id = $1", ticketID)
+	return err
+}
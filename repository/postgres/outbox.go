@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+)
+
+const outboxColumns = "id, channel, event, ticket_id, recipient_id, message, status, attempts, next_attempt_at, last_error, created_at, sent_at"
+
+type outboxRepo struct {
+	q querier
+}
+
+func scanOutboxEntry(row scanner) (*models.OutboxEntry, error) {
+	var entry models.OutboxEntry
+	var lastError sql.NullString
+	var sentAt sql.NullTime
+
+	if err := row.Scan(
+		&entry.ID,
+		&entry.Channel,
+		&entry.Event,
+		&entry.TicketID,
+		&entry.RecipientID,
+		&entry.Message,
+		&entry.Status,
+		&entry.Attempts,
+		&entry.NextAttemptAt,
+		&lastError,
+		&entry.CreatedAt,
+		&sentAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if lastError.Valid {
+		entry.LastError = &lastError.String
+	}
+	if sentAt.Valid {
+		sentAtTime := sentAt.Time
+		entry.SentAt = &sentAtTime
+	}
+
+	return &entry, nil
+}
+
+func scanOutboxEntries(rows *sql.Rows) ([]models.OutboxEntry, error) {
+	defer rows.Close()
+
+	var entries []models.OutboxEntry
+	for rows.Next() {
+		entry, err := scanOutboxEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *outboxRepo) Enqueue(ctx context.Context, entries []models.OutboxEntry) error {
+	for i := range entries {
+		entry := &entries[i]
+		err := r.q.QueryRowContext(ctx,
+			"INSERT INTO notification_outbox (channel, event, ticket_id, recipient_id, message, status, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+			entry.Channel, entry.Event, entry.TicketID, entry.RecipientID, entry.Message, entry.Status, entry.NextAttemptAt, entry.CreatedAt,
+		).Scan(&entry.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *outboxRepo) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.OutboxEntry, error) {
+	// SKIP LOCKED позволяет нескольким экземплярам разбирать очередь без дублей
+	rows, err := r.q.QueryContext(ctx, `
+		UPDATE notification_outbox SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE status = $2 AND next_attempt_at <= $3
+			ORDER BY next_attempt_at, id
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+outboxColumns,
+		now.Add(lease), models.OutboxPending, now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboxEntries(rows)
+}
+
+func (r *outboxRepo) MarkSent(ctx context.Context, id int64, at time.Time) error {
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE notification_outbox SET status = $1, attempts = attempts + 1, sent_at = $2, last_error = NULL WHERE id = $3",
+		models.OutboxSent, at, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE notification_outbox SET attempts = $1, next_attempt_at = $2, last_error = $3 WHERE id = $4",
+		attempts, nextAttemptAt, lastError, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *outboxRepo) MarkDead(ctx context.Context, id int64, attempts int, lastError string) error {
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE notification_outbox SET status = $1, attempts = $2, last_error = $3 WHERE id = $4",
+		models.OutboxDead, attempts, lastError, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *outboxRepo) List(ctx context.Context, status string, page repository.Page) ([]models.OutboxEntry, error) {
+	query := "SELECT " + outboxColumns + " FROM notification_outbox"
+	var params []interface{}
+
+	if status != "" {
+		params = append(params, status)
+		query += " WHERE status = $1"
+	}
+	query, params = keyset{id: "id", desc: true}.apply(query, status != "", page, params)
+
+	rows, err := r.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := scanOutboxEntries(rows)
+	if page.Before != nil {
+		reverse(entries)
+	}
+	return entries, err
+}
+
+func (r *outboxRepo) GetByID(ctx context.Context, id int64) (*models.OutboxEntry, error) {
+	entry, err := scanOutboxEntry(r.q.QueryRowContext(ctx, "SELECT "+outboxColumns+" FROM notification_outbox WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return entry, err
+}
+
+func (r *outboxRepo) Replay(ctx context.Context, id int64, now time.Time) error {
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE notification_outbox SET status = $1, attempts = 0, next_attempt_at = $2 WHERE id = $3 AND status = $4",
+		models.OutboxPending, now, id, models.OutboxDead,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"strconv"
+	"support_front_api/repository"
+)
+
+// keyCasts — типы SQL, к которым приводится текстовое значение ключа курсора
+var keyCasts = map[string]string{
+	repository.KeyInt:  "bigint",
+	repository.KeyText: "text",
+	repository.KeyTime: "timestamptz",
+}
+
+// keyset описывает порядок списка для keyset-пагинации: ключ сортировки и ID в одном направлении.
+// Выражение ключа не должно давать NULL, иначе сравнение строк с курсором не сработает.
+type keyset struct {
+	expr string // SQL-выражение ключа; пустое — порядок только по ID
+	kind string // тип ключа (repository.Key*)
+	id   string // столбец ID
+	desc bool
+}
+
+// apply дописывает к запросу условие курсора, ORDER BY и LIMIT/OFFSET страницы.
+// hasWhere сообщает, что в запросе уже есть WHERE. Для page.Before порядок обращается,
+// поэтому результат нужно развернуть (см. reverse).
+func (k keyset) apply(query string, hasWhere bool, page repository.Page, params []interface{}) (string, []interface{}) {
+	param := func(value interface{}) string {
+		params = append(params, value)
+		return "$" + strconv.Itoa(len(params))
+	}
+
+	desc := k.desc
+	cursor := page.After
+	if page.Before != nil {
+		cursor, desc = page.Before, !desc
+	}
+
+	if cursor != nil {
+		op := " > "
+		if desc {
+			op = " < "
+		}
+		var cond string
+		if k.expr != "" {
+			cond = "(" + k.expr + ", " + k.id + ")" + op + "(" + param(cursor.Key) + "::" + keyCasts[k.kind] + ", " + param(cursor.ID) + ")"
+		} else {
+			cond = k.id + op + param(cursor.ID)
+		}
+		if hasWhere {
+			query += " AND " + cond
+		} else {
+			query += " WHERE " + cond
+		}
+	}
+
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
+	}
+	if k.expr != "" {
+		query += " ORDER BY " + k.expr + direction + ", " + k.id + direction
+	} else {
+		query += " ORDER BY " + k.id + direction
+	}
+
+	if page.Limit > 0 {
+		query += " LIMIT " + param(page.Limit)
+	}
+	if cursor == nil && page.Offset > 0 {
+		query += " OFFSET " + param(page.Offset)
+	}
+	return query, params
+}
+
+// reverse разворачивает срез на месте
+func reverse[T any](items []T) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+}
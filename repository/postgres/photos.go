@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+const photoColumns = "id, ticket_id, sender_type, sender_id, file_path, file_id, message_id, created_at"
+
+type photoRepo struct {
+	q querier
+}
+
+func scanPhoto(row scanner) (*models.TicketPhoto, error) {
+	var photo models.TicketPhoto
+	var messageID sql.NullInt32
+
+	if err := row.Scan(
+		&photo.ID,
+		&photo.TicketID,
+		&photo.SenderType,
+		&photo.SenderID,
+		&photo.FilePath,
+		&photo.FileID,
+		&messageID,
+		&photo.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if messageID.Valid {
+		msgID := int(messageID.Int32)
+		photo.MessageID = &msgID
+	}
+
+	return &photo, nil
+}
+
+func (r *photoRepo) ListByTicket(ctx context.Context, ticketID int) ([]models.TicketPhoto, error) {
+	rows, err := r.q.QueryContext(ctx,
+		"SELECT "+photoColumns+" FROM ticket_photos WHERE ticket_id = $1 ORDER BY created_at, id",
+		ticketID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var photos []models.TicketPhoto
+	for rows.Next() {
+		photo, err := scanPhoto(rows)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, *photo)
+	}
+
+	return photos, rows.Err()
+}
+
+func (r *photoRepo) GetByID(ctx context.Context, id int) (*models.TicketPhoto, error) {
+	photo, err := scanPhoto(r.q.QueryRowContext(ctx, "SELECT "+photoColumns+" FROM ticket_photos WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return photo, err
+}
+
+func (r *photoRepo) Create(ctx context.Context, photo *models.TicketPhoto) error {
+	return r.q.QueryRowContext(ctx, `
+		INSERT INTO ticket_photos
+		(ticket_id, sender_type, sender_id, file_path, file_id, message_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`,
+		photo.TicketID,
+		photo.SenderType,
+		photo.SenderID,
+		photo.FilePath,
+		photo.FileID,
+		photo.MessageID,
+		photo.CreatedAt,
+	).Scan(&photo.ID)
+}
+
+func (r *photoRepo) Delete(ctx context.Context, id int) error {
+	result, err := r.q.ExecContext(ctx, "DELETE FROM ticket_photos WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *photoRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	_, err := r.q.ExecContext(ctx, "DELETE FROM ticket_photos WHERE ticket_id = $1", ticketID)
+	return err
+}
+
+func (r *photoRepo) MoveToTicket(ctx context.Context, fromID, toID int) (int, error) {
+	result, err := r.q.ExecContext(ctx, "UPDATE ticket_photos SET ticket_id = $1 WHERE ticket_id = $2", toID, fromID)
+	if err != nil {
+		return 0, err
+	}
+	moved, err := result.RowsAffected()
+	return int(moved), err
+}
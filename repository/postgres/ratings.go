@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+const ratingColumns = "ticket_id, user_id, score, comment, agent_id, category, created_at"
+
+type ratingRepo struct {
+	q querier
+}
+
+func scanRating(row scanner) (*models.TicketRating, error) {
+	var rating models.TicketRating
+	var agentID sql.NullInt64
+
+	if err := row.Scan(
+		&rating.TicketID,
+		&rating.UserID,
+		&rating.Score,
+		&rating.Comment,
+		&agentID,
+		&rating.Category,
+		&rating.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if agentID.Valid {
+		rating.AgentID = &agentID.Int64
+	}
+	return &rating, nil
+}
+
+func (r *ratingRepo) GetByTicket(ctx context.Context, ticketID int) (*models.TicketRating, error) {
+	rating, err := scanRating(r.q.QueryRowContext(ctx, "SELECT "+ratingColumns+" FROM ticket_ratings WHERE ticket_id = $1", ticketID))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return rating, err
+}
+
+func (r *ratingRepo) Create(ctx context.Context, rating *models.TicketRating) (bool, error) {
+	// ticket_id — первичный ключ, поэтому повторная оценка не сохраняется
+	result, err := r.q.ExecContext(ctx, `
+		INSERT INTO ticket_ratings (ticket_id, user_id, score, comment, agent_id, category, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (ticket_id) DO NOTHING
+	`,
+		rating.TicketID,
+		rating.UserID,
+		rating.Score,
+		rating.Comment,
+		rating.AgentID,
+		rating.Category,
+		rating.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *ratingRepo) List(ctx context.Context, filter repository.RatingFilter) ([]models.TicketRating, error) {
+	var conds []string
+	var params []interface{}
+	if filter.From != nil {
+		params = append(params, *filter.From)
+		conds = append(conds, "created_at >= $"+strconv.Itoa(len(params)))
+	}
+	if filter.To != nil {
+		params = append(params, *filter.To)
+		conds = append(conds, "created_at < $"+strconv.Itoa(len(params)))
+	}
+
+	query := "SELECT " + ratingColumns + " FROM ticket_ratings"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	rows, err := r.q.QueryContext(ctx, query+" ORDER BY created_at, ticket_id", params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ratings []models.TicketRating
+	for rows.Next() {
+		rating, err := scanRating(rows)
+		if err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, *rating)
+	}
+
+	return ratings, rows.Err()
+}
+
+func (r *ratingRepo) DeleteByTicket(ctx context.Context, ticketID int) error {
+	_, err := r.q.ExecContext(ctx, "DELETE FROM ticket_ratings WHERE ticket_id = $1", ticketID)
+	return err
+}
@@ -0,0 +1,26 @@
+package postgres
+
+import "context"
+
+type routingRepo struct {
+	q querier
+}
+
+func (r *routingRepo) LockCursor(ctx context.Context, key string) (int64, error) {
+	// Строка создается при первом обращении, чтобы ее можно было заблокировать
+	if _, err := r.q.ExecContext(ctx,
+		"INSERT INTO routing_cursors (key) VALUES ($1) ON CONFLICT (key) DO NOTHING",
+		key,
+	); err != nil {
+		return 0, err
+	}
+
+	var agentID int64
+	err := r.q.QueryRowContext(ctx, "SELECT last_agent_id FROM routing_cursors WHERE key = $1 FOR UPDATE", key).Scan(&agentID)
+	return agentID, err
+}
+
+func (r *routingRepo) SetCursor(ctx context.Context, key string, agentID int64) error {
+	_, err := r.q.ExecContext(ctx, "UPDATE routing_cursors SET last_agent_id = $1 WHERE key = $2", agentID, key)
+	return err
+}
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"strconv"
+	"strings"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+// Маркеры найденных слов в ts_headline. Символы из области частного использования
+// не встречаются в тексте, поэтому после экранирования HTML их можно заменить на теги.
+const (
+	highlightStart = "\ue000"
+	highlightStop  = "\ue001"
+)
+
+var (
+	headlineMarkers        = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `"`
+	titleHeadlineOptions   = headlineMarkers + ", HighlightAll=true"
+	snippetHeadlineOptions = headlineMarkers + `, MinWords=10, MaxWords=30, MaxFragments=2, FragmentDelimiter=" … "`
+
+	highlightTags = strings.NewReplacer(highlightStart, "<b>", highlightStop, "</b>")
+)
+
+// searchTicketColumns — ticketColumns с псевдонимом таблицы для запросов с JOIN
+var searchTicketColumns = "t." + strings.ReplaceAll(ticketColumns, ", ", ", t.")
+
+// searchHits находит тикеты по заголовку и описанию и по сообщениям ($1 — текст запроса).
+// Ранг тикета — сумма ранга самого тикета и его самого релевантного сообщения.
+// Внутренние заметки пропускаются, если в запросе задан WithoutNotes.
+func searchHits(query repository.SearchQuery) string {
+	messageCond := "m.search_vector @@ q.query"
+	if query.WithoutNotes {
+		messageCond += " AND m.kind <> '" + models.MessageKindNote + "'"
+	}
+
+	return `WITH q AS (SELECT websearch_to_tsquery('russian', $1) AS query),
+hits AS (
+	SELECT t.id AS ticket_id, ts_rank_cd(t.search_vector, q.query) AS rank, NULL::integer AS message_id
+	FROM tickets t, q
+	WHERE t.search_vector @@ q.query
+	UNION ALL
+	(SELECT DISTINCT ON (m.ticket_id) m.ticket_id, ts_rank_cd(m.search_vector, q.query), m.id
+	FROM ticket_messages m, q
+	WHERE ` + messageCond + `
+	ORDER BY m.ticket_id, ts_rank_cd(m.search_vector, q.query) DESC, m.id)
+),
+ranked AS (
+	SELECT ticket_id, SUM(rank) AS rank, MAX(message_id) AS message_id
+	FROM hits
+	GROUP BY ticket_id
+)
+`
+}
+
+type searchRepo struct {
+	q querier
+}
+
+// highlight экранирует HTML и заменяет маркеры ts_headline тегами <b>
+func highlight(text string) string {
+	return highlightTags.Replace(html.EscapeString(text))
+}
+
+// extraScanner дописывает к столбцам тикета дополнительные столбцы запроса
+type extraScanner struct {
+	row   scanner
+	extra []interface{}
+}
+
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// searchWhere ограничивает найденные тикеты по запросу и исключает тикеты в корзине;
+// params начинаются с текста запроса
+func searchWhere(query repository.SearchQuery) (string, []interface{}) {
+	params := []interface{}{query.Text}
+	if query.UserID == nil {
+		return " WHERE t.deleted_at IS NULL", params
+	}
+	params = append(params, *query.UserID)
+	return " WHERE t.deleted_at IS NULL AND t.user_id = $" + strconv.Itoa(len(params)), params
+}
+
+func (r *searchRepo) Tickets(ctx context.Context, query repository.SearchQuery) ([]models.SearchResult, error) {
+	where, params := searchWhere(query)
+
+	params = append(params, titleHeadlineOptions, snippetHeadlineOptions)
+	titleOptions, snippetOptions := "$"+strconv.Itoa(len(params)-1), "$"+strconv.Itoa(len(params))
+
+	sqlQuery := searchHits(query) + "SELECT " + searchTicketColumns + ", r.rank, r.message_id," +
+		" ts_headline('russian', t.title, q.query, " + titleOptions + ")," +
+		" ts_headline('russian', COALESCE(m.message, t.description), q.query, " + snippetOptions + ")" +
+		" FROM ranked r JOIN tickets t ON t.id = r.ticket_id" +
+		" LEFT JOIN ticket_messages m ON m.id = r.message_id CROSS JOIN q" +
+		where + " ORDER BY r.rank DESC, t.created_at DESC, t.id DESC"
+
+	if query.Limit > 0 {
+		params = append(params, query.Limit)
+		sqlQuery += " LIMIT $" + strconv.Itoa(len(params))
+	}
+	if query.Offset > 0 {
+		params = append(params, query.Offset)
+		sqlQuery += " OFFSET $" + strconv.Itoa(len(params))
+	}
+
+	rows, err := r.q.QueryContext(ctx, sqlQuery, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		var result models.SearchResult
+		var messageID sql.NullInt64
+		var title, snippet string
+
+		ticket, err := scanTicket(extraScanner{row: rows, extra: []interface{}{&result.Rank, &messageID, &title, &snippet}})
+		if err != nil {
+			return nil, err
+		}
+
+		result.Ticket = *ticket
+		if messageID.Valid {
+			id := int(messageID.Int64)
+			result.MessageID = &id
+		}
+		result.TitleHighlight = highlight(title)
+		result.Snippet = highlight(snippet)
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+func (r *searchRepo) Count(ctx context.Context, query repository.SearchQuery) (int, error) {
+	where, params := searchWhere(query)
+
+	var count int
+	err := r.q.QueryRowContext(ctx,
+		searchHits(query)+"SELECT COUNT(*) FROM ranked r JOIN tickets t ON t.id = r.ticket_id"+where,
+		params...,
+	).Scan(&count)
+	return count, err
+}
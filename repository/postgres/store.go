@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"support_front_api/repository"
+)
+
+// querier — общие методы *sql.DB и *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// scanner — общий метод *sql.Row и *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// Store реализует repository.Store поверх PostgreSQL
+type Store struct {
+	db *sql.DB
+	q  querier
+	tx bool
+	// depth — глубина вложенных InTx, задает имя точки сохранения
+	depth int
+}
+
+// NewStore создает хранилище на основе подключения к PostgreSQL
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db, q: db}
+}
+
+func (s *Store) Tickets() repository.TicketRepository   { return &ticketRepo{q: s.q} }
+func (s *Store) Messages() repository.MessageRepository { return &messageRepo{q: s.q} }
+func (s *Store) Photos() repository.PhotoRepository     { return &photoRepo{q: s.q} }
+func (s *Store) History() repository.HistoryRepository  { return &historyRepo{q: s.q} }
+func (s *Store) Links() repository.LinkRepository       { return &linkRepo{q: s.q} }
+func (s *Store) Ratings() repository.RatingRepository   { return &ratingRepo{q: s.q} }
+func (s *Store) Users() repository.UserRepository       { return &userRepo{q: s.q} }
+func (s *Store) Agents() repository.AgentRepository     { return &agentRepo{q: s.q} }
+func (s *Store) CannedResponses() repository.CannedResponseRepository {
+	return &cannedRepo{q: s.q}
+}
+func (s *Store) CustomFields() repository.CustomFieldRepository {
+	return &customFieldRepo{q: s.q}
+}
+func (s *Store) Routing() repository.RoutingRepository        { return &routingRepo{q: s.q} }
+func (s *Store) Escalations() repository.EscalationRepository { return &escalationRepo{q: s.q} }
+func (s *Store) Locks() repository.LockRepository             { return &lockRepo{q: s.q} }
+func (s *Store) Search() repository.SearchRepository          { return &searchRepo{q: s.q} }
+func (s *Store) Outbox() repository.OutboxRepository          { return &outboxRepo{q: s.q} }
+
+// InTx выполняет fn в транзакции базы данных
+func (s *Store) InTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	if s.tx {
+		return s.inSavepoint(ctx, fn)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании транзакции: %v", err)
+	}
+
+	if err := fn(&Store{db: s.db, q: tx, tx: true}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при фиксации транзакции: %v", err)
+	}
+	return nil
+}
+
+// inSavepoint выполняет вложенный InTx в точке сохранения, чтобы ошибка fn
+// не прерывала внешнюю транзакцию
+func (s *Store) inSavepoint(ctx context.Context, fn func(tx repository.Store) error) error {
+	name := fmt.Sprintf("nested_%d", s.depth+1)
+	if _, err := s.q.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("ошибка при создании точки сохранения: %v", err)
+	}
+
+	if err := fn(&Store{db: s.db, q: s.q, tx: true, depth: s.depth + 1}); err != nil {
+		if _, rbErr := s.q.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%v; ошибка при откате к точке сохранения: %v", err, rbErr)
+		}
+		return err
+	}
+
+	if _, err := s.q.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("ошибка при освобождении точки сохранения: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const ticketColumns = "id, user_id, title, description, status, priority, category, created_at, closed_at, resolved_at, reopened_at, assignee_id, first_response_at, first_response_due, resolution_due, first_response_risk_at, resolution_risk_at, waiting_since, stale_reminded_at, auto_closed_at, deleted_at, deleted_by, merged_into_id, custom_fields"
+
+type ticketRepo struct {
+	q querier
+}
+
+func scanTicket(row scanner) (*models.Ticket, error) {
+	var ticket models.Ticket
+	var closedAt, resolvedAt, reopenedAt sql.NullTime
+	var firstResponseAt, firstResponseDue, resolutionDue sql.NullTime
+	var firstResponseRiskAt, resolutionRiskAt sql.NullTime
+	var waitingSince, staleRemindedAt, autoClosedAt, deletedAt sql.NullTime
+	var assigneeID, deletedBy, mergedIntoID sql.NullInt64
+	var customFields []byte
+
+	if err := row.Scan(
+		&ticket.ID,
+		&ticket.UserID,
+		&ticket.Title,
+		&ticket.Description,
+		&ticket.Status,
+		&ticket.Priority,
+		&ticket.Category,
+		&ticket.CreatedAt,
+		&closedAt,
+		&resolvedAt,
+		&reopenedAt,
+		&assigneeID,
+		&firstResponseAt,
+		&firstResponseDue,
+		&resolutionDue,
+		&firstResponseRiskAt,
+		&resolutionRiskAt,
+		&waitingSince,
+		&staleRemindedAt,
+		&autoClosedAt,
+		&deletedAt,
+		&deletedBy,
+		&mergedIntoID,
+		&customFields,
+	); err != nil {
+		return nil, err
+	}
+
+	ticket.ClosedAt = nullTimePtr(closedAt)
+	ticket.ResolvedAt = nullTimePtr(resolvedAt)
+	ticket.ReopenedAt = nullTimePtr(reopenedAt)
+	if assigneeID.Valid {
+		ticket.AssigneeID = &assigneeID.Int64
+	}
+	ticket.FirstResponseAt = nullTimePtr(firstResponseAt)
+	ticket.FirstResponseDue = nullTimePtr(firstResponseDue)
+	ticket.ResolutionDue = nullTimePtr(resolutionDue)
+	ticket.FirstResponseRiskAt = nullTimePtr(firstResponseRiskAt)
+	ticket.ResolutionRiskAt = nullTimePtr(resolutionRiskAt)
+	ticket.WaitingSince = nullTimePtr(waitingSince)
+	ticket.StaleRemindedAt = nullTimePtr(staleRemindedAt)
+	ticket.AutoClosedAt = nullTimePtr(autoClosedAt)
+	ticket.DeletedAt = nullTimePtr(deletedAt)
+	if deletedBy.Valid {
+		ticket.DeletedBy = &deletedBy.Int64
+	}
+	if mergedIntoID.Valid {
+		id := int(mergedIntoID.Int64)
+		ticket.MergedIntoID = &id
+	}
+	if err := json.Unmarshal(customFields, &ticket.CustomFields); err != nil {
+		return nil, err
+	}
+	if len(ticket.CustomFields) == 0 {
+		ticket.CustomFields = nil
+	}
+
+	return &ticket, nil
+}
+
+// ticketWhere строит условие WHERE по фильтру
+func ticketWhere(filter repository.TicketFilter) (string, []interface{}) {
+	conds := []string{"deleted_at IS NULL"}
+	if filter.Deleted {
+		conds[0] = "deleted_at IS NOT NULL"
+	}
+	var params []interface{}
+
+	// param добавляет параметр запроса и возвращает его плейсхолдер
+	param := func(value interface{}) string {
+		params = append(params, value)
+		return "$" + strconv.Itoa(len(params))
+	}
+
+	if filter.Status != "" {
+		conds = append(conds, "status = "+param(filter.Status))
+	}
+	if filter.Priority != "" {
+		conds = append(conds, "priority = "+param(filter.Priority))
+	}
+	if len(filter.Categories) > 0 {
+		conds = append(conds, "category = ANY("+param(pq.Array(filter.Categories))+")")
+	}
+	if filter.UserID != nil {
+		conds = append(conds, "user_id = "+param(*filter.UserID))
+	}
+	if filter.AssigneeID != nil {
+		conds = append(conds, "assignee_id = "+param(*filter.AssigneeID))
+	}
+	if filter.Unassigned {
+		conds = append(conds, "assignee_id IS NULL")
+	}
+	if filter.SLA != nil {
+		conds = append(conds, slaCondition(*filter.SLA, param))
+	}
+	if filter.CreatedFrom != nil {
+		conds = append(conds, "created_at >= "+param(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conds = append(conds, "created_at < "+param(*filter.CreatedTo))
+	}
+	if filter.ClosedFrom != nil {
+		conds = append(conds, "closed_at >= "+param(*filter.ClosedFrom))
+	}
+	if filter.ClosedTo != nil {
+		conds = append(conds, "closed_at < "+param(*filter.ClosedTo))
+	}
+	if len(filter.CustomFields) > 0 {
+		// Значения фильтра — строки, числа и логические значения, они всегда записываются в JSON;
+		// условие @> использует GIN-индекс по custom_fields
+		values, _ := json.Marshal(filter.CustomFields)
+		conds = append(conds, "custom_fields @> "+param(string(values))+"::jsonb")
+	}
+	if filter.Unanswered != nil {
+		// Последнее сообщение тикета от клиента; тикет без сообщений ответа не ждет, заметки не учитываются
+		lastSender := "(SELECT m.sender_type FROM ticket_messages m WHERE m.ticket_id = tickets.id AND m.kind <> '" + models.MessageKindNote + "'" +
+			" ORDER BY m.created_at DESC, m.id DESC LIMIT 1)"
+		if *filter.Unanswered {
+			conds = append(conds, lastSender+" = 'user'")
+		} else {
+			conds = append(conds, "COALESCE("+lastSender+", '') <> 'user'")
+		}
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), params
+}
+
+// slaCondition повторяет в SQL вычисление models.Ticket.ComputeSLAStatus
+func slaCondition(filter repository.SLAFilter, param func(interface{}) string) string {
+	now := param(filter.Now) + "::timestamptz"
+
+	hasDeadline := "(first_response_due IS NOT NULL OR resolution_due IS NOT NULL)"
+	breached := "((first_response_due IS NOT NULL AND COALESCE(first_response_at, " + now + ") > first_response_due)" +
+		" OR (resolution_due IS NOT NULL AND COALESCE(resolved_at, closed_at, " + now + ") > resolution_due))"
+	atRisk := "((first_response_due IS NOT NULL AND first_response_at IS NULL AND " + now + " > first_response_risk_at)" +
+		" OR (resolution_due IS NOT NULL AND resolved_at IS NULL AND closed_at IS NULL AND " + now + " > resolution_risk_at))"
+
+	switch filter.Status {
+	case models.SLABreached:
+		return breached
+	case models.SLAAtRisk:
+		return "(NOT " + breached + " AND " + atRisk + ")"
+	default:
+		return "(" + hasDeadline + " AND NOT " + breached + " AND NOT " + atRisk + ")"
+	}
+}
+
+// ticketSortExpressions — SQL-выражения полей сортировки. Запрос собирается только из них,
+// значение поля из запроса клиента в SQL не попадает.
+var ticketSortExpressions = map[string]string{
+	repository.TicketSortID:               "id",
+	repository.TicketSortCreatedAt:        "created_at",
+	repository.TicketSortClosedAt:         "COALESCE(closed_at, 'infinity'::timestamptz)",
+	repository.TicketSortPriority:         "array_position(" + textArray(models.Priorities) + ", priority::text)",
+	repository.TicketSortStatus:           "array_position(" + textArray(models.Statuses) + ", status::text)",
+	repository.TicketSortCategory:         "category",
+	repository.TicketSortUserID:           "user_id",
+	repository.TicketSortFirstResponseDue: "COALESCE(first_response_due, 'infinity'::timestamptz)",
+	repository.TicketSortResolutionDue:    "COALESCE(resolution_due, 'infinity'::timestamptz)",
+	repository.TicketSortDeletedAt:        "COALESCE(deleted_at, 'infinity'::timestamptz)",
+}
+
+// textArray записывает известные коды литералом ARRAY['a', 'b']::text[]
+func textArray(values []string) string {
+	return "ARRAY['" + strings.Join(values, "', '") + "']::text[]"
+}
+
+// ticketKeyset возвращает порядок выборки по полю сортировки с ID для однозначного порядка
+func ticketKeyset(sort repository.TicketSort) keyset {
+	expr, ok := ticketSortExpressions[sort.Field]
+	if !ok {
+		sort = repository.DefaultTicketSort
+		expr = ticketSortExpressions[sort.Field]
+	}
+	if sort.Field == repository.TicketSortID {
+		expr = ""
+	}
+	return keyset{expr: expr, kind: repository.TicketSortKind(sort.Field), id: "id", desc: sort.Desc}
+}
+
+func (r *ticketRepo) List(ctx context.Context, filter repository.TicketFilter) ([]models.Ticket, error) {
+	where, params := ticketWhere(filter)
+	query, params := ticketKeyset(filter.Sort).apply("SELECT "+ticketColumns+" FROM tickets"+where, true, filter.Page, params)
+
+	tickets, err := r.query(ctx, query, params...)
+	if filter.Before != nil {
+		reverse(tickets)
+	}
+	return tickets, err
+}
+
+// query выполняет запрос, выбирающий ticketColumns, и сканирует тикеты
+func (r *ticketRepo) query(ctx context.Context, query string, params ...interface{}) ([]models.Ticket, error) {
+	rows, err := r.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickets []models.Ticket
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, *ticket)
+	}
+
+	return tickets, rows.Err()
+}
+
+func (r *ticketRepo) Count(ctx context.Context, filter repository.TicketFilter) (int, error) {
+	where, params := ticketWhere(filter)
+
+	var count int
+	err := r.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM tickets"+where, params...).Scan(&count)
+	return count, err
+}
+
+func (r *ticketRepo) GetByID(ctx context.Context, id int) (*models.Ticket, error) {
+	ticket, err := scanTicket(r.q.QueryRowContext(ctx, "SELECT "+ticketColumns+" FROM tickets WHERE id = $1 AND deleted_at IS NULL", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return ticket, err
+}
+
+func (r *ticketRepo) GetForUpdate(ctx context.Context, id int) (*models.Ticket, error) {
+	ticket, err := scanTicket(r.q.QueryRowContext(ctx, "SELECT "+ticketColumns+" FROM tickets WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return ticket, err
+}
+
+func (r *ticketRepo) Exists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	err := r.q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM tickets WHERE id = $1 AND deleted_at IS NULL)", id).Scan(&exists)
+	return exists, err
+}
+
+func (r *ticketRepo) Create(ctx context.Context, ticket *models.Ticket) error {
+	customFields, err := customFieldsJSON(ticket.CustomFields)
+	if err != nil {
+		return err
+	}
+
+	return r.q.QueryRowContext(ctx,
+		`INSERT INTO tickets (user_id, title, description, status, priority, category, created_at,
+			first_response_due, resolution_due, first_response_risk_at, resolution_risk_at, custom_fields)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+		ticket.UserID, ticket.Title, ticket.Description, ticket.Status, ticket.Priority, ticket.Category, ticket.CreatedAt,
+		ticket.FirstResponseDue, ticket.ResolutionDue, ticket.FirstResponseRiskAt, ticket.ResolutionRiskAt, string(customFields),
+	).Scan(&ticket.ID)
+}
+
+func (r *ticketRepo) Update(ctx context.Context, ticket *models.Ticket) error {
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE tickets SET status = $1, priority = $2, category = $3, closed_at = $4, resolved_at = $5, reopened_at = $6,
+			assignee_id = $7, first_response_at = $8, first_response_due = $9, resolution_due = $10,
+			first_response_risk_at = $11, resolution_risk_at = $12,
+			waiting_since = $13, stale_reminded_at = $14, auto_closed_at = $15, merged_into_id = $16
+			WHERE id = $17 AND deleted_at IS NULL`,
+		ticket.Status, ticket.Priority, ticket.Category, ticket.ClosedAt, ticket.ResolvedAt, ticket.ReopenedAt,
+		ticket.AssigneeID, ticket.FirstResponseAt, ticket.FirstResponseDue, ticket.ResolutionDue,
+		ticket.FirstResponseRiskAt, ticket.ResolutionRiskAt,
+		ticket.WaitingSince, ticket.StaleRemindedAt, ticket.AutoClosedAt, ticket.MergedIntoID, ticket.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *ticketRepo) Delete(ctx context.Context, id int) error {
+	result, err := r.q.ExecContext(ctx, "DELETE FROM tickets WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *ticketRepo) SoftDelete(ctx context.Context, id int, at time.Time, by *int64) error {
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE tickets SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL",
+		at, by, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *ticketRepo) GetDeleted(ctx context.Context, id int) (*models.Ticket, error) {
+	ticket, err := scanTicket(r.q.QueryRowContext(ctx, "SELECT "+ticketColumns+" FROM tickets WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return ticket, err
+}
+
+func (r *ticketRepo) Restore(ctx context.Context, id int) error {
+	result, err := r.q.ExecContext(ctx, "UPDATE tickets SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *ticketRepo) ListDeleted(ctx context.Context, before time.Time) ([]models.Ticket, error) {
+	return r.query(ctx,
+		"SELECT "+ticketColumns+" FROM tickets WHERE deleted_at < $1 ORDER BY deleted_at, id",
+		before,
+	)
+}
+
+func (r *ticketRepo) CountActiveByAssignee(ctx context.Context) (map[int64]int, error) {
+	rows, err := r.q.QueryContext(ctx,
+		"SELECT assignee_id, COUNT(*) FROM tickets WHERE assignee_id IS NOT NULL AND deleted_at IS NULL AND status NOT IN ($1, $2) GROUP BY assignee_id",
+		models.StatusResolved, models.StatusClosed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var agentID int64
+		var count int
+		if err := rows.Scan(&agentID, &count); err != nil {
+			return nil, err
+		}
+		counts[agentID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *ticketRepo) ListActiveByAssignee(ctx context.Context, agentID int64) ([]models.Ticket, error) {
+	return r.query(ctx,
+		"SELECT "+ticketColumns+" FROM tickets WHERE assignee_id = $1 AND deleted_at IS NULL AND status NOT IN ($2, $3) ORDER BY id",
+		agentID, models.StatusResolved, models.StatusClosed,
+	)
+}
+
+func (r *ticketRepo) ListWaiting(ctx context.Context, before time.Time) ([]models.Ticket, error) {
+	return r.query(ctx,
+		"SELECT "+ticketColumns+" FROM tickets WHERE waiting_since < $1 AND deleted_at IS NULL AND status NOT IN ($2, $3) ORDER BY waiting_since",
+		before, models.StatusResolved, models.StatusClosed,
+	)
+}
+
+// nullTimePtr превращает sql.NullTime в указатель, nil для NULL
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	value := t.Time
+	return &value
+}
+
+// expectAffected возвращает ErrNotFound, если запрос не затронул ни одной строки
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
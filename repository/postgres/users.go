@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"support_front_api/models"
+	"support_front_api/repository"
+)
+
+const userColumns = "id, full_name, phone, location_lat, location_lng, birth_date, is_registered, registered_at"
+
+type userRepo struct {
+	q querier
+}
+
+func scanUser(row scanner) (*models.User, error) {
+	var user models.User
+	var birthDate sql.NullTime
+	var registeredAt sql.NullTime
+
+	if err := row.Scan(
+		&user.ID,
+		&user.FullName,
+		&user.Phone,
+		&user.LocationLat,
+		&user.LocationLng,
+		&birthDate,
+		&user.IsRegistered,
+		&registeredAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if birthDate.Valid {
+		user.BirthDate = birthDate.Time
+	}
+
+	if registeredAt.Valid {
+		regAt := registeredAt.Time
+		user.RegisteredAt = &regAt
+	}
+
+	return &user, nil
+}
+
+func (r *userRepo) List(ctx context.Context, page repository.Page) ([]models.User, error) {
+	query, params := keyset{id: "id"}.apply("SELECT "+userColumns+" FROM users", false, page, nil)
+	rows, err := r.q.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+
+	if page.Before != nil {
+		reverse(users)
+	}
+	return users, rows.Err()
+}
+
+func (r *userRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count)
+	return count, err
+}
+
+func (r *userRepo) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	user, err := scanUser(r.q.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	return user, err
+}
+
+func (r *userRepo) Exists(ctx context.Context, id int64) (bool, error) {
+	var exists bool
+	err := r.q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", id).Scan(&exists)
+	return exists, err
+}
+
+func (r *userRepo) Create(ctx context.Context, user *models.User) error {
+	_, err := r.q.ExecContext(ctx,
+		"INSERT INTO users ("+userColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		user.ID,
+		user.FullName,
+		user.Phone,
+		user.LocationLat,
+		user.LocationLng,
+		user.BirthDate,
+		user.IsRegistered,
+		user.RegisteredAt,
+	)
+	return err
+}
+
+func (r *userRepo) Update(ctx context.Context, user *models.User) error {
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE users SET full_name = $1, phone = $2, location_lat = $3, location_lng = $4, birth_date = $5, is_registered = $6, registered_at = $7 WHERE id = $8",
+		user.FullName,
+		user.Phone,
+		user.LocationLat,
+		user.LocationLng,
+		user.BirthDate,
+		user.IsRegistered,
+		user.RegisteredAt,
+		user.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
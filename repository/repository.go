@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"support_front_api/models"
+	"time"
+)
+
+// ErrNotFound возвращается, когда запрошенная запись отсутствует
+var ErrNotFound = errors.New("запись не найдена")
+
+// TicketFilter задает условия выборки тикетов
+type TicketFilter struct {
+	Status   string
+	Priority string
+	// Categories оставляет тикеты любой из перечисленных категорий
+	Categories []string
+	UserID     *int64
+	AssigneeID *int64
+	// Unassigned оставляет только тикеты без ответственного
+	Unassigned bool
+	SLA        *SLAFilter
+	// CreatedFrom/CreatedTo и ClosedFrom/ClosedTo ограничивают время создания и закрытия: [From, To)
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	ClosedFrom  *time.Time
+	ClosedTo    *time.Time
+	// Unanswered: true — последнее сообщение в тикете от клиента, false — нет
+	Unanswered *bool
+	// CustomFields оставляет тикеты, у которых дополнительные поля равны приведенным
+	// значениям (см. models.CustomField.Normalize)
+	CustomFields map[string]interface{}
+	// Deleted выбирает тикеты из корзины; без него тикеты в корзине не выбираются
+	Deleted bool
+	// Sort — порядок выборки; по умолчанию новые первыми
+	Sort TicketSort
+	// Page — страница выборки; ключ курсора соответствует полю Sort (см. TicketCursor)
+	Page
+}
+
+// Поля сортировки тикетов. Приоритет и статус сортируются в порядке models.Priorities
+// и models.Statuses, тикеты без даты (closed_at, сроки SLA) идут после тикетов с датой.
+const (
+	TicketSortID               = "id"
+	TicketSortCreatedAt        = "created_at"
+	TicketSortClosedAt         = "closed_at"
+	TicketSortPriority         = "priority"
+	TicketSortStatus           = "status"
+	TicketSortCategory         = "category"
+	TicketSortUserID           = "user_id"
+	TicketSortFirstResponseDue = "first_response_due"
+	TicketSortResolutionDue    = "resolution_due"
+	TicketSortDeletedAt        = "deleted_at"
+)
+
+// TicketSortFields перечисляет допустимые поля сортировки тикетов
+var TicketSortFields = []string{
+	TicketSortID,
+	TicketSortCreatedAt,
+	TicketSortClosedAt,
+	TicketSortPriority,
+	TicketSortStatus,
+	TicketSortCategory,
+	TicketSortUserID,
+	TicketSortFirstResponseDue,
+	TicketSortResolutionDue,
+	TicketSortDeletedAt,
+}
+
+// TicketSort задает поле и направление сортировки; при равенстве тикеты упорядочиваются по ID в том же направлении
+type TicketSort struct {
+	Field string
+	Desc  bool
+}
+
+// DefaultTicketSort — новые тикеты первыми
+var DefaultTicketSort = TicketSort{Field: TicketSortCreatedAt, Desc: true}
+
+// ValidTicketSortField проверяет, что по полю можно сортировать
+func ValidTicketSortField(field string) bool {
+	for _, f := range TicketSortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// SLAFilter отбирает тикеты по состоянию SLA на момент Now (см. models.Ticket.ComputeSLAStatus)
+type SLAFilter struct {
+	Status string
+	Now    time.Time
+}
+
+// TicketRepository описывает хранилище тикетов. Тикеты в корзине видны только
+// через фильтр TicketFilter.Deleted, GetDeleted, Restore и ListDeleted.
+type TicketRepository interface {
+	// List возвращает тикеты по фильтру в порядке filter.Sort
+	List(ctx context.Context, filter TicketFilter) ([]models.Ticket, error)
+	// Count возвращает количество тикетов по фильтру без учета страницы
+	Count(ctx context.Context, filter TicketFilter) (int, error)
+	GetByID(ctx context.Context, id int) (*models.Ticket, error)
+	// GetForUpdate загружает тикет и блокирует его до конца транзакции
+	GetForUpdate(ctx context.Context, id int) (*models.Ticket, error)
+	Exists(ctx context.Context, id int) (bool, error)
+	// Create сохраняет тикет и заполняет его ID
+	Create(ctx context.Context, ticket *models.Ticket) error
+	// Update сохраняет изменяемые поля тикета
+	Update(ctx context.Context, ticket *models.Ticket) error
+	// Delete удаляет тикет без возможности восстановления; связанные записи удаляются вызывающим
+	Delete(ctx context.Context, id int) error
+	// SoftDelete переносит тикет в корзину; ErrNotFound, если тикета нет или он уже в корзине
+	SoftDelete(ctx context.Context, id int, at time.Time, by *int64) error
+	// GetDeleted загружает тикет из корзины и блокирует его до конца транзакции
+	GetDeleted(ctx context.Context, id int) (*models.Ticket, error)
+	// Restore возвращает тикет из корзины; ErrNotFound, если его там нет
+	Restore(ctx context.Context, id int) error
+	// ListDeleted возвращает тикеты, перенесенные в корзину раньше before
+	ListDeleted(ctx context.Context, before time.Time) ([]models.Ticket, error)
+	// CountActiveByAssignee возвращает количество нерешенных тикетов по ответственным
+	CountActiveByAssignee(ctx context.Context) (map[int64]int, error)
+	// ListActiveByAssignee возвращает нерешенные тикеты сотрудника по возрастанию ID
+	ListActiveByAssignee(ctx context.Context, agentID int64) ([]models.Ticket, error)
+	// ListWaiting возвращает нерешенные тикеты, ожидающие ответа клиента с момента раньше before
+	ListWaiting(ctx context.Context, before time.Time) ([]models.Ticket, error)
+}
+
+// SearchQuery задает полнотекстовый поиск тикетов
+type SearchQuery struct {
+	// Text — запрос в синтаксисе websearch: слова, "фраза", -исключение, or
+	Text   string
+	UserID *int64
+	// WithoutNotes не ищет во внутренних заметках — для клиентов
+	WithoutNotes bool
+	Limit        int
+	Offset       int
+}
+
+// SearchRepository описывает полнотекстовый поиск по тикетам и их сообщениям
+type SearchRepository interface {
+	// Tickets возвращает найденные тикеты, самые релевантные первыми
+	Tickets(ctx context.Context, query SearchQuery) ([]models.SearchResult, error)
+	// Count возвращает количество найденных тикетов без учета Limit/Offset
+	Count(ctx context.Context, query SearchQuery) (int, error)
+}
+
+// MessageFilter задает условия выборки сообщений тикета
+type MessageFilter struct {
+	// WithoutNotes исключает внутренние заметки — для клиентов
+	WithoutNotes bool
+	// Page — страница выборки; ключ курсора — время сообщения (KeyTime), нулевая страница — все сообщения
+	Page
+}
+
+// MessageRepository описывает хранилище сообщений тикетов
+type MessageRepository interface {
+	// ListByTicket возвращает сообщения тикета в хронологическом порядке
+	ListByTicket(ctx context.Context, ticketID int, filter MessageFilter) ([]models.TicketMessage, error)
+	// CountByTicket возвращает количество сообщений тикета без учета страницы
+	CountByTicket(ctx context.Context, ticketID int, filter MessageFilter) (int, error)
+	// Create сохраняет сообщение и заполняет его ID
+	Create(ctx context.Context, message *models.TicketMessage) error
+	DeleteByTicket(ctx context.Context, ticketID int) error
+	// MoveToTicket переносит все сообщения тикета fromID в тикет toID и возвращает их количество
+	MoveToTicket(ctx context.Context, fromID, toID int) (int, error)
+}
+
+// PhotoRepository описывает хранилище фотографий тикетов
+type PhotoRepository interface {
+	ListByTicket(ctx context.Context, ticketID int) ([]models.TicketPhoto, error)
+	GetByID(ctx context.Context, id int) (*models.TicketPhoto, error)
+	// Create сохраняет фотографию и заполняет ее ID
+	Create(ctx context.Context, photo *models.TicketPhoto) error
+	Delete(ctx context.Context, id int) error
+	DeleteByTicket(ctx context.Context, ticketID int) error
+	// MoveToTicket переносит все фотографии тикета fromID в тикет toID и возвращает их количество
+	MoveToTicket(ctx context.Context, fromID, toID int) (int, error)
+}
+
+// LinkRepository описывает связи между тикетами. Связи хранятся в направлении
+// models.TicketLink.Canonical; между двумя тикетами может быть только одна связь.
+type LinkRepository interface {
+	// ListByTicket возвращает связи, в которых участвует тикет, упорядоченные по ID
+	ListByTicket(ctx context.Context, ticketID int) ([]models.TicketLink, error)
+	GetByID(ctx context.Context, id int64) (*models.TicketLink, error)
+	// Create сохраняет связь и заполняет ее ID; false, если тикеты уже связаны
+	// или у дочернего тикета уже есть родительский
+	Create(ctx context.Context, link *models.TicketLink) (bool, error)
+	Delete(ctx context.Context, id int64) error
+	DeleteByTicket(ctx context.Context, ticketID int) error
+}
+
+// RatingFilter задает период отчета по оценкам: оценки, поставленные в [From, To)
+type RatingFilter struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// RatingRepository описывает оценки тикетов клиентами
+type RatingRepository interface {
+	// GetByTicket возвращает оценку тикета или ErrNotFound, если ее нет
+	GetByTicket(ctx context.Context, ticketID int) (*models.TicketRating, error)
+	// Create сохраняет оценку; false, если тикет уже оценен
+	Create(ctx context.Context, rating *models.TicketRating) (bool, error)
+	// List возвращает оценки за период в порядке их создания
+	List(ctx context.Context, filter RatingFilter) ([]models.TicketRating, error)
+	DeleteByTicket(ctx context.Context, ticketID int) error
+}
+
+// CustomFieldRepository описывает схему дополнительных полей тикетов по категориям
+type CustomFieldRepository interface {
+	// List возвращает поля категории, а для пустой категории — поля всех категорий,
+	// упорядоченные по категории и порядку добавления
+	List(ctx context.Context, category string) ([]models.CustomField, error)
+	GetByID(ctx context.Context, id int64) (*models.CustomField, error)
+	// Create сохраняет поле и заполняет его ID; false, если в категории уже есть поле с таким ключом
+	Create(ctx context.Context, field *models.CustomField) (bool, error)
+	// Update сохраняет подпись, обязательность и варианты поля
+	Update(ctx context.Context, field *models.CustomField) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// HistoryRepository описывает историю изменений тикетов
+type HistoryRepository interface {
+	// Add сохраняет записи истории и заполняет их ID
+	Add(ctx context.Context, entries ...models.TicketHistoryEntry) error
+	// ListByTicket возвращает историю тикета в хронологическом порядке; ключ курсора — время записи (KeyTime)
+	ListByTicket(ctx context.Context, ticketID int, page Page) ([]models.TicketHistoryEntry, error)
+	CountByTicket(ctx context.Context, ticketID int) (int, error)
+	DeleteByTicket(ctx context.Context, ticketID int) error
+}
+
+// Видимость шаблонов ответов в CannedResponseFilter.Scope
+const (
+	CannedScopeShared   = "shared"
+	CannedScopePersonal = "personal"
+)
+
+// CannedResponseFilter задает условия выборки шаблонов ответов
+type CannedResponseFilter struct {
+	// VisibleTo — сотрудник, для которого выбираются общие шаблоны и его личные
+	VisibleTo int64
+	// Scope оставляет только общие (CannedScopeShared) или только личные (CannedScopePersonal) шаблоны
+	Scope string
+	// Category оставляет шаблоны категории и шаблоны без категории
+	Category string
+	// Page — страница выборки; шаблоны упорядочены по категории и ID, ключ курсора — категория (KeyText)
+	Page
+}
+
+// CannedResponseRepository описывает библиотеку шаблонов ответов
+type CannedResponseRepository interface {
+	List(ctx context.Context, filter CannedResponseFilter) ([]models.CannedResponse, error)
+	// Count возвращает количество шаблонов по фильтру без учета страницы
+	Count(ctx context.Context, filter CannedResponseFilter) (int, error)
+	GetByID(ctx context.Context, id int64) (*models.CannedResponse, error)
+	// Create сохраняет шаблон и заполняет его ID
+	Create(ctx context.Context, response *models.CannedResponse) error
+	Update(ctx context.Context, response *models.CannedResponse) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// AgentRepository описывает справочник сотрудников поддержки
+type AgentRepository interface {
+	// List возвращает сотрудников, упорядоченных по ID; курсор без ключа (KeyNone)
+	List(ctx context.Context, page Page) ([]models.Agent, error)
+	Count(ctx context.Context) (int, error)
+	GetByID(ctx context.Context, id int64) (*models.Agent, error)
+	Exists(ctx context.Context, id int64) (bool, error)
+	Create(ctx context.Context, agent *models.Agent) error
+	Update(ctx context.Context, agent *models.Agent) error
+	Delete(ctx context.Context, id int64) error
+	// ListAvailable возвращает активных сотрудников на смене, упорядоченных по ID
+	ListAvailable(ctx context.Context) ([]models.Agent, error)
+}
+
+// RoutingRepository хранит позиции round-robin
+type RoutingRepository interface {
+	// LockCursor возвращает ID последнего назначенного сотрудника для ключа (0, если назначений
+	// не было) и блокирует позицию до конца транзакции
+	LockCursor(ctx context.Context, key string) (int64, error)
+	SetCursor(ctx context.Context, key string, agentID int64) error
+}
+
+// EscalationRepository хранит сработавшие эскалации SLA
+type EscalationRepository interface {
+	// Record сохраняет эскалацию; false, если она уже была записана
+	Record(ctx context.Context, escalation models.Escalation) (bool, error)
+	// Exists сообщает, записана ли эскалация тикета по сроку и уровню
+	Exists(ctx context.Context, escalation models.Escalation) (bool, error)
+	DeleteByTicket(ctx context.Context, ticketID int) error
+}
+
+// LockRepository выдает блокировки для фоновых задач, работающих в нескольких экземплярах
+type LockRepository interface {
+	// TryLock пытается взять блокировку key до конца транзакции, не дожидаясь ее освобождения.
+	// Вызывается внутри InTx.
+	TryLock(ctx context.Context, key int64) (bool, error)
+}
+
+// UserRepository описывает хранилище пользователей
+type UserRepository interface {
+	// List возвращает пользователей, упорядоченных по ID; курсор без ключа (KeyNone)
+	List(ctx context.Context, page Page) ([]models.User, error)
+	Count(ctx context.Context) (int, error)
+	GetByID(ctx context.Context, id int64) (*models.User, error)
+	Exists(ctx context.Context, id int64) (bool, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+}
+
+// OutboxRepository описывает очередь уведомлений
+type OutboxRepository interface {
+	// Enqueue добавляет записи в очередь и заполняет их ID
+	Enqueue(ctx context.Context, entries []models.OutboxEntry) error
+	// ClaimDue выбирает до limit готовых к отправке записей и откладывает их
+	// следующую попытку на lease, чтобы другие экземпляры их не взяли
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]models.OutboxEntry, error)
+	MarkSent(ctx context.Context, id int64, at time.Time) error
+	// MarkFailed сохраняет неудачную попытку и время следующей
+	MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string) error
+	// MarkDead переводит запись в статус dead после исчерпания попыток
+	MarkDead(ctx context.Context, id int64, attempts int, lastError string) error
+	// List возвращает записи со статусом status (пустой — все), новые первыми; курсор без ключа (KeyNone)
+	List(ctx context.Context, status string, page Page) ([]models.OutboxEntry, error)
+	GetByID(ctx context.Context, id int64) (*models.OutboxEntry, error)
+	// Replay возвращает запись из dead в очередь с обнулением попыток
+	Replay(ctx context.Context, id int64, now time.Time) error
+}
+
+// Store объединяет репозитории одного хранилища
+type Store interface {
+	Tickets() TicketRepository
+	Messages() MessageRepository
+	Photos() PhotoRepository
+	History() HistoryRepository
+	Links() LinkRepository
+	Ratings() RatingRepository
+	CustomFields() CustomFieldRepository
+	Users() UserRepository
+	Agents() AgentRepository
+	CannedResponses() CannedResponseRepository
+	Routing() RoutingRepository
+	Escalations() EscalationRepository
+	Locks() LockRepository
+	Search() SearchRepository
+	Outbox() OutboxRepository
+
+	// InTx выполняет fn в транзакции: при ошибке все изменения отменяются.
+	// Вложенный вызов InTx выполняется в уже открытой транзакции и при ошибке
+	// отменяет только изменения fn; внешняя транзакция может продолжить работу.
+	InTx(ctx context.Context, fn func(tx Store) error) error
+}
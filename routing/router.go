@@ -0,0 +1,215 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"support_front_api/config"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"time"
+)
+
+// Стратегии назначения тикетов
+const (
+	StrategyNone       = ""
+	StrategyRoundRobin = "round_robin"
+	StrategyLeastOpen  = "least_open"
+	StrategySkills     = "skills"
+)
+
+// policy — стратегия и навыки для категории
+type policy struct {
+	strategy string
+	skills   []string
+}
+
+// Router назначает тикеты сотрудникам на смене по стратегии категории.
+// Все методы работают через переданное хранилище, поэтому внутри InTx
+// назначение сохраняется в одной транзакции с изменением тикета.
+type Router struct {
+	fallback   policy
+	categories map[string]policy
+}
+
+// NewRouter создает маршрутизатор по конфигурации и проверяет названия стратегий
+func NewRouter(cfg config.RoutingConfig) (*Router, error) {
+	if err := checkStrategy(cfg.Strategy); err != nil {
+		return nil, err
+	}
+
+	r := &Router{
+		fallback:   policy{strategy: cfg.Strategy},
+		categories: make(map[string]policy, len(cfg.Categories)),
+	}
+	for category, categoryCfg := range cfg.Categories {
+		if err := checkStrategy(categoryCfg.Strategy); err != nil {
+			return nil, fmt.Errorf("категория '%s': %w", category, err)
+		}
+		r.categories[models.NormalizeCategory(category)] = policy{
+			strategy: categoryCfg.Strategy,
+			skills:   categoryCfg.Skills,
+		}
+	}
+
+	return r, nil
+}
+
+func checkStrategy(strategy string) error {
+	switch strategy {
+	case StrategyNone, StrategyRoundRobin, StrategyLeastOpen, StrategySkills:
+		return nil
+	}
+	return fmt.Errorf("неизвестная стратегия назначения: '%s'", strategy)
+}
+
+// policyFor возвращает стратегию категории; для skills без списка навыков навыком служит сама категория
+func (r *Router) policyFor(category string) policy {
+	p, ok := r.categories[category]
+	if !ok {
+		p = r.fallback
+	}
+	if p.strategy == StrategySkills && len(p.skills) == 0 {
+		p.skills = []string{category}
+	}
+	return p
+}
+
+// Pick выбирает сотрудника для тикета среди доступных, кроме exclude (0 — без исключений).
+// Возвращает nil, если автоназначение выключено или подходящих сотрудников нет.
+func (r *Router) Pick(ctx context.Context, store repository.Store, ticket *models.Ticket, exclude int64) (*int64, error) {
+	p := r.policyFor(ticket.Category)
+	if p.strategy == StrategyNone {
+		return nil, nil
+	}
+
+	available, err := store.Agents().ListAvailable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.Agent
+	for _, agent := range available {
+		if agent.ID == exclude {
+			continue
+		}
+		if p.strategy == StrategySkills && !agent.HasAnySkill(p.skills) {
+			continue
+		}
+		candidates = append(candidates, agent)
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	if p.strategy == StrategyRoundRobin {
+		return pickRoundRobin(ctx, store, ticket.Category, candidates)
+	}
+	// skills среди подходящих сотрудников выбирает наименее загруженного
+	return pickLeastOpen(ctx, store, candidates)
+}
+
+// pickRoundRobin выбирает следующего по ID сотрудника после последнего назначенного в категории
+func pickRoundRobin(ctx context.Context, store repository.Store, category string, candidates []models.Agent) (*int64, error) {
+	key := "category:" + category
+
+	last, err := store.Routing().LockCursor(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	next := candidates[0].ID
+	for _, agent := range candidates {
+		if agent.ID > last {
+			next = agent.ID
+			break
+		}
+	}
+
+	if err := store.Routing().SetCursor(ctx, key, next); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// pickLeastOpen выбирает сотрудника с наименьшим числом нерешенных тикетов, при равенстве — с меньшим ID
+func pickLeastOpen(ctx context.Context, store repository.Store, candidates []models.Agent) (*int64, error) {
+	counts, err := store.Tickets().CountActiveByAssignee(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	best := candidates[0].ID
+	for _, agent := range candidates[1:] {
+		if counts[agent.ID] < counts[best] {
+			best = agent.ID
+		}
+	}
+	return &best, nil
+}
+
+// Assign назначает новый тикет по стратегии его категории и записывает назначение в историю.
+// Возвращает false, если сотрудник не выбран.
+func (r *Router) Assign(ctx context.Context, store repository.Store, ticket *models.Ticket) (bool, error) {
+	agentID, err := r.Pick(ctx, store, ticket, 0)
+	if err != nil || agentID == nil {
+		return false, err
+	}
+
+	if err := r.setAssignee(ctx, store, ticket, agentID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Reassign передает нерешенные тикеты ушедшего со смены сотрудника другим доступным
+// сотрудникам. Если автоназначение в категории выключено или подходящих сотрудников нет,
+// тикет остается за прежним ответственным.
+// Тикеты блокируются до конца транзакции store, поэтому вызывать Reassign нужно внутри InTx.
+// Возвращает количество переназначенных тикетов.
+func (r *Router) Reassign(ctx context.Context, store repository.Store, agentID int64) (int, error) {
+	tickets, err := store.Tickets().ListActiveByAssignee(ctx, agentID)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, listed := range tickets {
+		// Перечитываем тикет под блокировкой: с момента выборки его могли изменить
+		ticket, err := store.Tickets().GetForUpdate(ctx, listed.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return moved, err
+		}
+		if ticket.AssigneeID == nil || *ticket.AssigneeID != agentID || !models.IsActiveStatus(ticket.Status) {
+			continue
+		}
+
+		next, err := r.Pick(ctx, store, ticket, agentID)
+		if err != nil {
+			return moved, err
+		}
+		if next == nil {
+			continue
+		}
+		if err := r.setAssignee(ctx, store, ticket, next); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+
+	return moved, nil
+}
+
+// setAssignee сохраняет ответственного и запись истории от имени системы
+func (r *Router) setAssignee(ctx context.Context, store repository.Store, ticket *models.Ticket, agentID *int64) error {
+	oldValue, newValue := models.AssigneeValue(ticket.AssigneeID), models.AssigneeValue(agentID)
+
+	ticket.AssigneeID = agentID
+	if err := store.Tickets().Update(ctx, ticket); err != nil {
+		return err
+	}
+	return store.History().Add(ctx, models.NewHistoryEntry(ticket.ID, models.HistoryFieldAssignee, oldValue, newValue, models.SystemActor(), time.Now()))
+}
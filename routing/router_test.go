@@ -0,0 +1,82 @@
+package routing
+
+import (
+	"context"
+	"support_front_api/config"
+	"support_front_api/models"
+	"support_front_api/repository"
+	"support_front_api/repository/memory"
+	"testing"
+	"time"
+)
+
+func TestReassign(t *testing.T) {
+	const leaving, other = 10, 11
+
+	tests := []struct {
+		name         string
+		strategy     string
+		otherOnShift bool
+		wantMoved    int
+		wantAssignee int64
+	}{
+		{"автоназначение выключено", StrategyNone, true, 0, leaving},
+		{"нет подходящих сотрудников", StrategyLeastOpen, false, 0, leaving},
+		{"есть подходящий сотрудник", StrategyLeastOpen, true, 1, other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := memory.NewStore()
+
+			for _, agent := range []models.Agent{
+				{ID: leaving, IsActive: true, OnShift: false},
+				{ID: other, IsActive: true, OnShift: tt.otherOnShift},
+			} {
+				agent := agent
+				if err := store.Agents().Create(ctx, &agent); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			assignee := int64(leaving)
+			ticket := models.Ticket{
+				UserID:     5,
+				Status:     models.StatusInProgress,
+				Priority:   models.DefaultPriority,
+				Category:   models.DefaultCategory,
+				AssigneeID: &assignee,
+				CreatedAt:  time.Now(),
+			}
+			if err := store.Tickets().Create(ctx, &ticket); err != nil {
+				t.Fatal(err)
+			}
+
+			router, err := NewRouter(config.RoutingConfig{Strategy: tt.strategy})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var moved int
+			err = store.InTx(ctx, func(tx repository.Store) error {
+				moved, err = router.Reassign(ctx, tx, leaving)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if moved != tt.wantMoved {
+				t.Errorf("переназначено %d, ожидалось %d", moved, tt.wantMoved)
+			}
+
+			saved, err := store.Tickets().GetByID(ctx, ticket.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.AssigneeID == nil || *saved.AssigneeID != tt.wantAssignee {
+				t.Errorf("ответственный %s, ожидался %d", models.AssigneeValue(saved.AssigneeID), tt.wantAssignee)
+			}
+		})
+	}
+}
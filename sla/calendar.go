@@ -0,0 +1,274 @@
+package sla
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"support_front_api/config"
+	"time"
+
+	// Часовые пояса календарей не должны зависеть от tzdata в системе
+	_ "time/tzdata"
+)
+
+// CalendarAlways — имя круглосуточного календаря, который не нужно описывать в конфигурации
+const CalendarAlways = "24x7"
+
+// shortDayCut — на сколько сокращается предпраздничный рабочий день (ст. 95 ТК РФ)
+const shortDayCut = time.Hour
+
+// weekdays сопоставляет ключи конфигурации дням недели
+var weekdays = map[string]time.Weekday{
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+	"sun": time.Sunday,
+}
+
+// interval — рабочий интервал дня в минутах от полуночи
+type interval struct {
+	start int
+	end   int
+}
+
+// date — календарная дата без времени
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	return date{t.Year(), t.Month(), t.Day()}
+}
+
+// holidaysFile — формат файла праздников: даты в виде YYYY-MM-DD
+type holidaysFile struct {
+	// Holidays — нерабочие праздничные дни и выходные, перенесенные на будни
+	Holidays []string `json:"holidays"`
+	// Workdays — выходные дни, ставшие рабочими из-за переноса
+	Workdays []string `json:"workdays"`
+	// ShortDays — предпраздничные дни, сокращенные на час
+	ShortDays []string `json:"short_days"`
+}
+
+// Calendar отсчитывает рабочее время по часам недели с учетом праздников и переносов.
+// nil-календарь считает время круглосуточно.
+type Calendar struct {
+	loc  *time.Location
+	week [7][]interval
+	// regular — часы перенесенного рабочего дня: часы первого рабочего дня недели, начиная с понедельника
+	regular   []interval
+	holidays  map[date]bool
+	workdays  map[date]bool
+	shortDays map[date]bool
+}
+
+// NewCalendar создает календарь по конфигурации и загружает файл праздников
+func NewCalendar(cfg config.CalendarConfig) (*Calendar, error) {
+	c := &Calendar{
+		loc:       time.UTC,
+		holidays:  make(map[date]bool),
+		workdays:  make(map[date]bool),
+		shortDays: make(map[date]bool),
+	}
+
+	if cfg.TimeZone != "" {
+		loc, err := time.LoadLocation(cfg.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("неизвестный часовой пояс '%s': %w", cfg.TimeZone, err)
+		}
+		c.loc = loc
+	}
+
+	for key, ranges := range cfg.Hours {
+		weekday, ok := weekdays[strings.ToLower(key)]
+		if !ok {
+			return nil, fmt.Errorf("неизвестный день недели '%s', ожидается mon..sun", key)
+		}
+		intervals, err := parseIntervals(ranges)
+		if err != nil {
+			return nil, fmt.Errorf("часы '%s': %w", key, err)
+		}
+		c.week[weekday] = intervals
+	}
+
+	for _, weekday := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		if len(c.week[weekday]) > 0 {
+			c.regular = c.week[weekday]
+			break
+		}
+	}
+	if c.regular == nil {
+		return nil, fmt.Errorf("в календаре нет рабочих часов")
+	}
+
+	if cfg.HolidaysFile != "" {
+		if err := c.loadHolidays(cfg.HolidaysFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// parseIntervals разбирает интервалы вида "09:00-18:00" и проверяет, что они идут по порядку и не пересекаются
+func parseIntervals(ranges []string) ([]interval, error) {
+	intervals := make([]interval, 0, len(ranges))
+	for _, value := range ranges {
+		from, to, ok := strings.Cut(value, "-")
+		if !ok {
+			return nil, fmt.Errorf("неверный интервал '%s', ожидается ЧЧ:ММ-ЧЧ:ММ", value)
+		}
+		start, err := parseClock(from)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(to)
+		if err != nil {
+			return nil, err
+		}
+		if start >= end {
+			return nil, fmt.Errorf("интервал '%s' пуст", value)
+		}
+		intervals = append(intervals, interval{start: start, end: end})
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start < intervals[j].start })
+	for i := 1; i < len(intervals); i++ {
+		if intervals[i].start < intervals[i-1].end {
+			return nil, fmt.Errorf("интервалы пересекаются")
+		}
+	}
+	return intervals, nil
+}
+
+// parseClock разбирает время ЧЧ:ММ в минуты от полуночи; допускается 24:00
+func parseClock(value string) (int, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(strings.TrimSpace(value), "%d:%d", &hours, &minutes); err != nil {
+		return 0, fmt.Errorf("неверное время '%s', ожидается ЧЧ:ММ", value)
+	}
+	total := hours*60 + minutes
+	if hours < 0 || minutes < 0 || minutes > 59 || total > 24*60 {
+		return 0, fmt.Errorf("неверное время '%s'", value)
+	}
+	return total, nil
+}
+
+// loadHolidays читает праздники, перенесенные рабочие и сокращенные дни из файла
+func (c *Calendar) loadHolidays(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла праздников: %w", err)
+	}
+
+	var file holidaysFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("ошибка разбора файла праздников %s: %w", path, err)
+	}
+
+	for _, list := range []struct {
+		values []string
+		into   map[date]bool
+	}{
+		{file.Holidays, c.holidays},
+		{file.Workdays, c.workdays},
+		{file.ShortDays, c.shortDays},
+	} {
+		for _, value := range list.values {
+			day, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return fmt.Errorf("неверная дата '%s' в файле праздников %s", value, path)
+			}
+			list.into[dateOf(day)] = true
+		}
+	}
+	return nil
+}
+
+// intervals возвращает рабочие интервалы дня day (полночь в часовом поясе календаря)
+func (c *Calendar) intervals(day time.Time) []interval {
+	key := dateOf(day)
+	if c.holidays[key] {
+		return nil
+	}
+
+	hours := c.week[day.Weekday()]
+	if len(hours) == 0 && c.workdays[key] {
+		hours = c.regular
+	}
+	if len(hours) > 0 && c.shortDays[key] {
+		last := hours[len(hours)-1]
+		if cut := int(shortDayCut / time.Minute); last.end-last.start > cut {
+			hours = append(hours[:len(hours)-1:len(hours)-1], interval{start: last.start, end: last.end - cut})
+		}
+	}
+	return hours
+}
+
+// bounds возвращает начало и конец интервала в день day
+func (c *Calendar) bounds(day time.Time, iv interval) (time.Time, time.Time) {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, iv.start, 0, 0, c.loc)
+	to := time.Date(day.Year(), day.Month(), day.Day(), 0, iv.end, 0, 0, c.loc)
+	return from, to
+}
+
+// startOfDay возвращает полночь дня момента t в часовом поясе календаря
+func (c *Calendar) startOfDay(t time.Time) time.Time {
+	local := t.In(c.loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// Add возвращает момент, когда от start пройдет d рабочего времени
+func (c *Calendar) Add(start time.Time, d time.Duration) time.Time {
+	if c == nil {
+		return start.Add(d)
+	}
+
+	for day := c.startOfDay(start); ; day = day.AddDate(0, 0, 1) {
+		for _, iv := range c.intervals(day) {
+			from, to := c.bounds(day, iv)
+			if !to.After(start) {
+				continue
+			}
+			if from.Before(start) {
+				from = start
+			}
+			span := to.Sub(from)
+			if d <= span {
+				return from.Add(d)
+			}
+			d -= span
+		}
+	}
+}
+
+// Between возвращает рабочее время между from и to
+func (c *Calendar) Between(from, to time.Time) time.Duration {
+	if c == nil {
+		return to.Sub(from)
+	}
+
+	var total time.Duration
+	for day := c.startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, iv := range c.intervals(day) {
+			start, end := c.bounds(day, iv)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+	}
+	return total
+}
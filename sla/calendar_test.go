@@ -0,0 +1,148 @@
+package sla
+
+import (
+	"os"
+	"path/filepath"
+	"support_front_api/config"
+	"testing"
+	"time"
+)
+
+// testCalendar — пятидневка с обедом по Москве. В ноябре 2024: 1 ноября (пятница) сокращенный
+// день, 2 ноября (суббота) рабочий по переносу, 4 ноября (понедельник) праздник.
+func testCalendar(t *testing.T) *Calendar {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "holidays.json")
+	content := `{"holidays": ["2024-11-04"], "workdays": ["2024-11-02"], "short_days": ["2024-11-01"]}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hours := []string{"09:00-13:00", "14:00-18:00"}
+	calendar, err := NewCalendar(config.CalendarConfig{
+		TimeZone: "Europe/Moscow",
+		Hours: map[string][]string{
+			"mon": hours, "tue": hours, "wed": hours, "thu": hours, "fri": hours,
+		},
+		HolidaysFile: path,
+	})
+	if err != nil {
+		t.Fatalf("NewCalendar: %v", err)
+	}
+	return calendar
+}
+
+func msk(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestCalendarAdd(t *testing.T) {
+	calendar := testCalendar(t)
+
+	tests := []struct {
+		name  string
+		start string
+		add   time.Duration
+		want  string
+	}{
+		{"внутри интервала", "2024-10-29 10:00", 2 * time.Hour, "2024-10-29 12:00"},
+		{"через обед", "2024-10-29 12:00", 2 * time.Hour, "2024-10-29 15:00"},
+		{"на следующий день", "2024-10-29 17:00", 2 * time.Hour, "2024-10-30 10:00"},
+		{"до начала дня", "2024-10-29 07:00", time.Hour, "2024-10-29 10:00"},
+		{"ровно до конца дня", "2024-10-29 17:00", time.Hour, "2024-10-29 18:00"},
+		{"с выходного", "2024-10-27 12:00", time.Hour, "2024-10-28 10:00"},
+		{"сокращенный день и рабочая суббота", "2024-11-01 16:00", 2 * time.Hour, "2024-11-02 10:00"},
+		{"через праздник", "2024-11-02 17:30", time.Hour, "2024-11-05 09:30"},
+		{"нулевой срок", "2024-10-29 10:00", 0, "2024-10-29 10:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calendar.Add(msk(t, tt.start), tt.add)
+			if want := msk(t, tt.want); !got.Equal(want) {
+				t.Errorf("Add(%s, %v) = %v, ожидалось %v", tt.start, tt.add, got, want)
+			}
+		})
+	}
+}
+
+func TestCalendarBetween(t *testing.T) {
+	calendar := testCalendar(t)
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     time.Duration
+	}{
+		{"внутри дня через обед", "2024-10-29 10:00", "2024-10-29 15:00", 4 * time.Hour},
+		{"нерабочее время", "2024-10-29 18:30", "2024-10-30 08:00", 0},
+		{"выходные", "2024-10-25 17:00", "2024-10-28 10:00", 2 * time.Hour},
+		{"сокращенный день, перенос и праздник", "2024-11-01 09:00", "2024-11-05 09:00", 15 * time.Hour},
+		{"конец раньше начала", "2024-10-29 15:00", "2024-10-29 10:00", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.Between(msk(t, tt.from), msk(t, tt.to)); got != tt.want {
+				t.Errorf("Between(%s, %s) = %v, ожидалось %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarAddBetweenAgree(t *testing.T) {
+	calendar := testCalendar(t)
+	start := msk(t, "2024-10-31 16:20")
+
+	for _, d := range []time.Duration{time.Minute, 90 * time.Minute, 8 * time.Hour, 24 * time.Hour} {
+		end := calendar.Add(start, d)
+		if got := calendar.Between(start, end); got != d {
+			t.Errorf("Between(start, Add(start, %v)) = %v", d, got)
+		}
+	}
+}
+
+func TestNilCalendar(t *testing.T) {
+	var calendar *Calendar
+	start := time.Date(2024, 11, 2, 23, 0, 0, 0, time.UTC)
+
+	if got := calendar.Add(start, 3*time.Hour); !got.Equal(start.Add(3 * time.Hour)) {
+		t.Errorf("Add = %v", got)
+	}
+	if got := calendar.Between(start, start.Add(5*time.Hour)); got != 5*time.Hour {
+		t.Errorf("Between = %v", got)
+	}
+}
+
+func TestNewCalendarErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.CalendarConfig
+	}{
+		{"нет рабочих часов", config.CalendarConfig{}},
+		{"неизвестный день", config.CalendarConfig{Hours: map[string][]string{"monday": {"09:00-18:00"}}}},
+		{"пустой интервал", config.CalendarConfig{Hours: map[string][]string{"mon": {"18:00-09:00"}}}},
+		{"пересечение интервалов", config.CalendarConfig{Hours: map[string][]string{"mon": {"09:00-13:00", "12:00-18:00"}}}},
+		{"неверное время", config.CalendarConfig{Hours: map[string][]string{"mon": {"09:00-25:00"}}}},
+		{"неизвестный часовой пояс", config.CalendarConfig{TimeZone: "Mars/Olympus", Hours: map[string][]string{"mon": {"09:00-18:00"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCalendar(tt.cfg); err == nil {
+				t.Error("ожидалась ошибка")
+			}
+		})
+	}
+}
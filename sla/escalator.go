@@ -0,0 +1,330 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"support_front_api/config"
+	"support_front_api/logger"
+	"support_front_api/models"
+	"support_front_api/notify"
+	"support_front_api/repository"
+	"time"
+)
+
+// escalationLockID — ключ advisory-блокировки: проверку в каждый момент выполняет один экземпляр
+const escalationLockID = 727100002
+
+// defaultEscalationInterval — период проверки тикетов по умолчанию
+const defaultEscalationInterval = time.Minute
+
+// rule — действия эскалации для срока и уровня
+type rule struct {
+	category      string
+	deadline      string
+	level         string
+	raisePriority bool
+	reassignTo    int64
+	notify        bool
+}
+
+// Escalator периодически проверяет сроки SLA нерешенных тикетов и выполняет
+// действия правил: повышает приоритет, передает тикет старшему сотруднику, уведомляет.
+// Каждая эскалация (тикет, срок, уровень) срабатывает один раз.
+type Escalator struct {
+	store    repository.Store
+	policies *Policies
+	outbox   *notify.Outbox
+	rules    []rule
+	interval time.Duration
+}
+
+// NewEscalator создает обработчик эскалаций и проверяет правила конфигурации
+func NewEscalator(store repository.Store, policies *Policies, outbox *notify.Outbox, cfg config.EscalationConfig) (*Escalator, error) {
+	e := &Escalator{
+		store:    store,
+		policies: policies,
+		outbox:   outbox,
+		interval: defaultEscalationInterval,
+	}
+	if cfg.IntervalSeconds > 0 {
+		e.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	}
+
+	for i, r := range cfg.Rules {
+		if r.Deadline != models.DeadlineFirstResponse && r.Deadline != models.DeadlineResolution {
+			return nil, fmt.Errorf("правило эскалации %d: неизвестный срок '%s'", i+1, r.Deadline)
+		}
+		if r.Level != models.SLAAtRisk && r.Level != models.SLABreached {
+			return nil, fmt.Errorf("правило эскалации %d: неизвестный уровень '%s'", i+1, r.Level)
+		}
+		if !r.RaisePriority && r.ReassignTo == 0 && !r.Notify {
+			return nil, fmt.Errorf("правило эскалации %d: не указано ни одного действия", i+1)
+		}
+		e.rules = append(e.rules, rule{
+			category:      models.NormalizeCategory(r.Category),
+			deadline:      r.Deadline,
+			level:         r.Level,
+			raisePriority: r.RaisePriority,
+			reassignTo:    r.ReassignTo,
+			notify:        r.Notify,
+		})
+	}
+
+	return e, nil
+}
+
+// rulesFor возвращает правила для категории, срока и уровня: правила категории,
+// а если у категории своих правил нет — общие
+func (e *Escalator) rulesFor(category, deadline, level string) []rule {
+	hasOwn := false
+	for _, r := range e.rules {
+		if r.category == category {
+			hasOwn = true
+			break
+		}
+	}
+
+	scope := ""
+	if hasOwn {
+		scope = category
+	}
+
+	var matched []rule
+	for _, r := range e.rules {
+		if r.category == scope && r.deadline == deadline && r.level == level {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Run проверяет тикеты с периодом interval до отмены ctx
+func (e *Escalator) Run(ctx context.Context) {
+	if len(e.rules) == 0 {
+		logger.LogInfo("Правила эскалации SLA не заданы, проверка сроков не запускается")
+		return
+	}
+	logger.LogInfo("Запущена эскалация SLA, правил: %d", len(e.rules))
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if escalated, err := e.RunOnce(ctx); err != nil {
+			logger.LogError("Ошибка при эскалации SLA: %v", err)
+		} else if escalated > 0 {
+			logger.LogInfo("Эскалировано сроков SLA: %d", escalated)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.LogInfo("Эскалация SLA остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce выполняет одну проверку и возвращает количество сработавших эскалаций.
+// Каждый тикет обрабатывается во вложенной транзакции: ошибка по одному тикету
+// записывается в журнал и не мешает эскалации остальных.
+// Если проверку уже выполняет другой экземпляр, возвращает 0.
+func (e *Escalator) RunOnce(ctx context.Context) (int, error) {
+	escalated := 0
+
+	err := e.store.InTx(ctx, func(tx repository.Store) error {
+		locked, err := tx.Locks().TryLock(ctx, escalationLockID)
+		if err != nil || !locked {
+			return err
+		}
+
+		now := time.Now()
+		tickets, err := e.candidates(ctx, tx, now)
+		if err != nil {
+			return err
+		}
+
+		for _, candidate := range tickets {
+			n := 0
+			err := tx.InTx(ctx, func(ticketTx repository.Store) error {
+				// Тикет перечитывается с блокировкой, чтобы не затереть параллельные изменения
+				ticket, err := ticketTx.Tickets().GetForUpdate(ctx, candidate.ID)
+				if err != nil {
+					return err
+				}
+				n, err = e.escalate(ctx, ticketTx, ticket, now)
+				return err
+			})
+			if err != nil {
+				logger.LogError("Ошибка при эскалации тикета %d: %v", candidate.ID, err)
+				continue
+			}
+			escalated += n
+		}
+		return nil
+	})
+
+	return escalated, err
+}
+
+// candidates возвращает нерешенные тикеты, у которых срок под угрозой или нарушен
+// и по этому сроку есть правила, еще не сработавшие для тикета
+func (e *Escalator) candidates(ctx context.Context, tx repository.Store, now time.Time) ([]models.Ticket, error) {
+	var tickets []models.Ticket
+	for _, status := range []string{models.SLABreached, models.SLAAtRisk} {
+		found, err := tx.Tickets().List(ctx, repository.TicketFilter{
+			SLA: &repository.SLAFilter{Status: status, Now: now},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, ticket := range found {
+			if !models.IsActiveStatus(ticket.Status) {
+				continue
+			}
+			pending, err := e.hasPending(ctx, tx, &ticket, now)
+			if err != nil {
+				return nil, err
+			}
+			if pending {
+				tickets = append(tickets, ticket)
+			}
+		}
+	}
+	return tickets, nil
+}
+
+// hasPending сообщает, есть ли у тикета эскалации, которые должны сработать и еще не записаны
+func (e *Escalator) hasPending(ctx context.Context, tx repository.Store, ticket *models.Ticket, now time.Time) (bool, error) {
+	for _, deadline := range []string{models.DeadlineFirstResponse, models.DeadlineResolution} {
+		level := ticket.DeadlineStatus(deadline, now)
+		if level != models.SLAAtRisk && level != models.SLABreached {
+			continue
+		}
+		if len(e.rulesFor(ticket.Category, deadline, level)) == 0 {
+			continue
+		}
+
+		recorded, err := tx.Escalations().Exists(ctx, models.Escalation{
+			TicketID: ticket.ID,
+			Deadline: deadline,
+			Level:    level,
+		})
+		if err != nil {
+			return false, err
+		}
+		if !recorded {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// escalate выполняет еще не сработавшие эскалации тикета и возвращает их количество
+func (e *Escalator) escalate(ctx context.Context, tx repository.Store, ticket *models.Ticket, now time.Time) (int, error) {
+	escalated := 0
+
+	for _, deadline := range []string{models.DeadlineFirstResponse, models.DeadlineResolution} {
+		level := ticket.DeadlineStatus(deadline, now)
+		if level != models.SLAAtRisk && level != models.SLABreached {
+			continue
+		}
+
+		rules := e.rulesFor(ticket.Category, deadline, level)
+		if len(rules) == 0 {
+			continue
+		}
+
+		recorded, err := tx.Escalations().Record(ctx, models.Escalation{
+			TicketID:  ticket.ID,
+			Deadline:  deadline,
+			Level:     level,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return escalated, err
+		}
+		if !recorded {
+			continue
+		}
+
+		if err := e.apply(ctx, tx, ticket, deadline, level, rules, now); err != nil {
+			return escalated, err
+		}
+		escalated++
+	}
+
+	return escalated, nil
+}
+
+// apply выполняет действия правил и записывает изменения в историю от имени системы.
+// Сроки SLA при повышении приоритета не пересчитываются, иначе эскалация повторялась бы по новому сроку.
+func (e *Escalator) apply(ctx context.Context, tx repository.Store, ticket *models.Ticket, deadline, level string, rules []rule, now time.Time) error {
+	actor := models.SystemActor()
+	changes := []models.TicketHistoryEntry{
+		models.NewHistoryEntry(ticket.ID, models.HistoryFieldEscalation, "", deadline+":"+level, actor, now),
+	}
+	notifyAssignee := false
+
+	for _, r := range rules {
+		if r.raisePriority {
+			if next, ok := models.NextPriority(ticket.Priority); ok {
+				changes = append(changes, models.NewHistoryEntry(ticket.ID, models.HistoryFieldPriority, ticket.Priority, next, actor, now))
+				ticket.Priority = next
+			}
+		}
+
+		if r.reassignTo != 0 && (ticket.AssigneeID == nil || *ticket.AssigneeID != r.reassignTo) {
+			if _, err := tx.Agents().GetByID(ctx, r.reassignTo); err != nil {
+				if !errors.Is(err, repository.ErrNotFound) {
+					return err
+				}
+				logger.LogWarning("Эскалация тикета %d: сотрудник %d не найден", ticket.ID, r.reassignTo)
+			} else {
+				lead := r.reassignTo
+				changes = append(changes, models.NewHistoryEntry(ticket.ID, models.HistoryFieldAssignee,
+					models.AssigneeValue(ticket.AssigneeID), models.AssigneeValue(&lead), actor, now))
+				ticket.AssigneeID = &lead
+			}
+		}
+
+		notifyAssignee = notifyAssignee || r.notify
+	}
+
+	if len(changes) > 1 {
+		if err := tx.Tickets().Update(ctx, ticket); err != nil {
+			return err
+		}
+	}
+	if err := tx.History().Add(ctx, changes...); err != nil {
+		return err
+	}
+
+	if !notifyAssignee {
+		return nil
+	}
+	if ticket.AssigneeID == nil {
+		logger.LogWarning("Эскалация тикета %d: нет ответственного для уведомления", ticket.ID)
+		return nil
+	}
+	return e.outbox.Enqueue(ctx, tx, notify.Notification{
+		Event:       notify.EventSLAEscalated,
+		TicketID:    ticket.ID,
+		RecipientID: *ticket.AssigneeID,
+		Message:     escalationMessage(ticket, deadline, level),
+	})
+}
+
+// escalationMessage формирует текст уведомления об эскалации
+func escalationMessage(ticket *models.Ticket, deadline, level string) string {
+	what := "Срок решения"
+	if deadline == models.DeadlineFirstResponse {
+		what = "Срок первого ответа"
+	}
+	state := "под угрозой"
+	if level == models.SLABreached {
+		state = "нарушен"
+	}
+	return fmt.Sprintf("%s по тикету %d %s (приоритет '%s')", what, ticket.ID, state, models.PriorityLabel(ticket.Priority, models.LangRU))
+}
@@ -0,0 +1,141 @@
+package sla
+
+import (
+	"fmt"
+	"support_front_api/config"
+	"support_front_api/models"
+	"time"
+)
+
+// defaultAtRiskPercent — остаток срока по умолчанию, при котором тикет считается под угрозой
+const defaultAtRiskPercent = 20
+
+// policyKey — приоритет и категория политики, пустое значение подходит любому
+type policyKey struct {
+	priority string
+	category string
+}
+
+// target — сроки политики в рабочем времени календаря, нулевой срок не отслеживается
+type target struct {
+	firstResponse time.Duration
+	resolution    time.Duration
+	calendar      *Calendar
+}
+
+// Policies вычисляет сроки SLA тикетов по политикам из конфигурации
+type Policies struct {
+	targets     map[policyKey]target
+	atRiskRatio float64
+}
+
+// NewPolicies создает политики SLA, загружает рабочие календари и проверяет приоритеты в конфигурации
+func NewPolicies(cfg config.SLAConfig) (*Policies, error) {
+	percent := cfg.AtRiskPercent
+	if percent <= 0 {
+		percent = defaultAtRiskPercent
+	}
+	if percent >= 100 {
+		return nil, fmt.Errorf("at_risk_percent должен быть меньше 100: %d", cfg.AtRiskPercent)
+	}
+
+	calendars := map[string]*Calendar{CalendarAlways: nil}
+	for name, calendarCfg := range cfg.Calendars {
+		if name == CalendarAlways {
+			return nil, fmt.Errorf("имя календаря '%s' зарезервировано", CalendarAlways)
+		}
+		calendar, err := NewCalendar(calendarCfg)
+		if err != nil {
+			return nil, fmt.Errorf("календарь '%s': %w", name, err)
+		}
+		calendars[name] = calendar
+	}
+
+	p := &Policies{
+		targets:     make(map[policyKey]target, len(cfg.Policies)),
+		atRiskRatio: float64(percent) / 100,
+	}
+	for _, policy := range cfg.Policies {
+		calendarName := policy.Calendar
+		if calendarName == "" {
+			calendarName = cfg.Calendar
+		}
+		if calendarName == "" {
+			calendarName = CalendarAlways
+		}
+		calendar, ok := calendars[calendarName]
+		if !ok {
+			return nil, fmt.Errorf("неизвестный календарь в политике SLA: '%s'", calendarName)
+		}
+
+		key := policyKey{category: models.NormalizeCategory(policy.Category)}
+		if policy.Priority != "" {
+			priority, ok := models.ParsePriority(policy.Priority)
+			if !ok {
+				return nil, fmt.Errorf("неизвестный приоритет в политике SLA: '%s'", policy.Priority)
+			}
+			key.priority = priority
+		}
+		if _, ok := p.targets[key]; ok {
+			return nil, fmt.Errorf("повторяющаяся политика SLA: приоритет '%s', категория '%s'", key.priority, key.category)
+		}
+		p.targets[key] = target{
+			firstResponse: time.Duration(policy.FirstResponseMinutes) * time.Minute,
+			resolution:    time.Duration(policy.ResolutionMinutes) * time.Minute,
+			calendar:      calendar,
+		}
+	}
+
+	return p, nil
+}
+
+// lookup находит самую точную политику для приоритета и категории
+func (p *Policies) lookup(priority, category string) (target, bool) {
+	for _, key := range []policyKey{
+		{priority: priority, category: category},
+		{priority: priority},
+		{category: category},
+		{},
+	} {
+		if t, ok := p.targets[key]; ok {
+			return t, true
+		}
+	}
+	return target{}, false
+}
+
+// deadline возвращает срок, отсчитанный от created по календарю, и момент, с которого срок под угрозой
+func (p *Policies) deadline(calendar *Calendar, created time.Time, target time.Duration) (*time.Time, *time.Time) {
+	if target <= 0 {
+		return nil, nil
+	}
+	due := calendar.Add(created, target)
+	riskAt := calendar.Add(created, target-time.Duration(float64(target)*p.atRiskRatio))
+	return &due, &riskAt
+}
+
+// Apply пересчитывает сроки первого ответа и решения тикета от времени его создания.
+// Сроки отсчитываются в рабочем времени календаря политики.
+func (p *Policies) Apply(ticket *models.Ticket) {
+	ticket.FirstResponseDue, ticket.FirstResponseRiskAt = nil, nil
+	ticket.ResolutionDue, ticket.ResolutionRiskAt = nil, nil
+
+	t, ok := p.lookup(ticket.Priority, ticket.Category)
+	if !ok {
+		return
+	}
+	ticket.FirstResponseDue, ticket.FirstResponseRiskAt = p.deadline(t.calendar, ticket.CreatedAt, t.firstResponse)
+	ticket.ResolutionDue, ticket.ResolutionRiskAt = p.deadline(t.calendar, ticket.CreatedAt, t.resolution)
+}
+
+// Status возвращает состояние SLA тикета на момент now
+func (p *Policies) Status(ticket *models.Ticket, now time.Time) string {
+	return ticket.ComputeSLAStatus(now)
+}
+
+// BusinessTime возвращает рабочее время между from и to по календарю политики тикета;
+// для тикетов без политики время считается круглосуточно
+func (p *Policies) BusinessTime(ticket *models.Ticket, from, to time.Time) time.Duration {
+	t, _ := p.lookup(ticket.Priority, ticket.Category)
+	return t.calendar.Between(from, to)
+}
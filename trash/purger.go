@@ -0,0 +1,171 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"support_front_api/config"
+	"support_front_api/logger"
+	"support_front_api/repository"
+	"time"
+)
+
+// purgerLockID — ключ advisory-блокировки: корзину в каждый момент очищает один экземпляр
+const purgerLockID = 727100004
+
+// defaultInterval — период проверки корзины по умолчанию
+const defaultInterval = time.Hour
+
+// Purger безвозвратно удаляет тикеты, пролежавшие в корзине дольше срока хранения
+type Purger struct {
+	store     repository.Store
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewPurger создает задачу очистки корзины и проверяет срок хранения
+func NewPurger(store repository.Store, cfg config.TrashConfig) (*Purger, error) {
+	if cfg.RetentionDays < 0 {
+		return nil, fmt.Errorf("срок хранения тикетов в корзине не может быть отрицательным")
+	}
+
+	p := &Purger{
+		store:     store,
+		retention: time.Duration(cfg.RetentionDays) * 24 * time.Hour,
+		interval:  defaultInterval,
+	}
+	if cfg.IntervalSeconds > 0 {
+		p.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	}
+	return p, nil
+}
+
+// Run очищает корзину с периодом interval до отмены ctx
+func (p *Purger) Run(ctx context.Context) {
+	if p.retention == 0 {
+		logger.LogInfo("Срок хранения корзины не задан, тикеты удаляются из нее только вручную")
+		return
+	}
+	logger.LogInfo("Запущена очистка корзины: тикеты хранятся %v", p.retention)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := p.RunOnce(ctx); err != nil {
+			logger.LogError("Ошибка при очистке корзины: %v", err)
+		} else if purged > 0 {
+			logger.LogInfo("Очистка корзины: удалено тикетов %d", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.LogInfo("Очистка корзины остановлена")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce удаляет тикеты с истекшим сроком хранения и возвращает их количество.
+// Если очистку уже выполняет другой экземпляр, возвращает 0.
+func (p *Purger) RunOnce(ctx context.Context) (int, error) {
+	if p.retention == 0 {
+		return 0, nil
+	}
+
+	var files []string
+	purged := 0
+	err := p.store.InTx(ctx, func(tx repository.Store) error {
+		locked, err := tx.Locks().TryLock(ctx, purgerLockID)
+		if err != nil || !locked {
+			return err
+		}
+
+		tickets, err := tx.Tickets().ListDeleted(ctx, time.Now().Add(-p.retention))
+		if err != nil {
+			return err
+		}
+		for _, ticket := range tickets {
+			paths, err := PurgeTicket(ctx, tx, ticket.ID)
+			if err != nil {
+				return fmt.Errorf("тикет %d: %w", ticket.ID, err)
+			}
+			files = append(files, paths...)
+			purged++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Файлы удаляются только после фиксации транзакции, иначе откат оставил бы записи без файлов
+	RemoveFiles(files)
+	return purged, nil
+}
+
+// PurgeTicket безвозвратно удаляет тикет вместе с сообщениями, фотографиями, историей,
+// эскалациями, связями и оценкой и возвращает пути файлов его фотографий. Вызывается внутри InTx;
+// файлы удаляются вызывающим после фиксации (см. RemoveFiles).
+func PurgeTicket(ctx context.Context, tx repository.Store, id int) ([]string, error) {
+	photos, err := tx.Photos().ListByTicket(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении фотографий тикета: %v", err)
+	}
+
+	if err := tx.History().DeleteByTicket(ctx, id); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении истории тикета: %v", err)
+	}
+	if err := tx.Ratings().DeleteByTicket(ctx, id); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении оценки тикета: %v", err)
+	}
+	if err := tx.Links().DeleteByTicket(ctx, id); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении связей тикета: %v", err)
+	}
+	if err := tx.Escalations().DeleteByTicket(ctx, id); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении эскалаций тикета: %v", err)
+	}
+	if err := tx.Photos().DeleteByTicket(ctx, id); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении фотографий тикета: %v", err)
+	}
+	if err := tx.Messages().DeleteByTicket(ctx, id); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении сообщений тикета: %v", err)
+	}
+	if err := tx.Tickets().Delete(ctx, id); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении тикета: %v", err)
+	}
+
+	files := make([]string, 0, len(photos))
+	for _, photo := range photos {
+		files = append(files, photo.FilePath)
+	}
+	return files, nil
+}
+
+// RemoveFiles удаляет файлы загрузок и опустевшие каталоги тикетов; ошибки только логируются
+func RemoveFiles(paths []string) {
+	dirs := make(map[string]struct{})
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logger.LogWarning("Не удалось удалить файл фотографии: %v", err)
+		}
+		// Фотографии лежат в каталоге uploads/<ID тикета>, сам каталог uploads не трогаем
+		if dir := filepath.Dir(path); isTicketDir(dir) {
+			dirs[dir] = struct{}{}
+		}
+	}
+
+	// Непустой каталог os.Remove не удаляет
+	for dir := range dirs {
+		os.Remove(dir)
+	}
+}
+
+// isTicketDir сообщает, что каталог назван по ID тикета
+func isTicketDir(dir string) bool {
+	_, err := strconv.Atoi(filepath.Base(dir))
+	return err == nil
+}